@@ -9,9 +9,9 @@ Bplint looks through given go source files for occurrence of tagged Bitpeek
 format strings then it checks every found one for common pitfalls.
 Bplint also prints on the console clear mapping from the string to input's bits:
 
-   $ bplint -m ampl bplint_test.go
+   $ bplint -m ampl lint/lint_test.go
 
-   --- Pic: "Example" in bplint_test.go line 22 ------------------------
+   --- Pic: "Example" in lint/lint_test.go line 22 ---------------------
    OK.
    bits:|63 3b 61|    60|   59|58 11b 48|47..     32b     ..16|15 16b 0|
                 ^      ^     ^         ^                     ^        ^|
@@ -23,16 +23,85 @@ Format string description is to be found at https://godoc.org/github.com/ohir/bi
 
 Usage
 
-This is a CLI tool intended for UTF-8 capable terminals. If you can not
-afford one you need to tinker with sources and change all non ascii
-prints yourself ;).
-
-  bplint [-q][-m MSTR] file.go [...]
+Maps are drawn for the terminal: with --render utf8 plain as shown
+above, with ascii for terminals that can not show UTF-8 (inserts are
+marked with ` and non ascii label characters shown as ?), with ansi
+colored: adjacent fields alternate colors and errors are bold red.
+Without the flag ascii is picked if TERM is unset or dumb, utf8 if
+NO_COLOR is set or output is not a terminal, ansi otherwise.
+
+  bplint [check] [-q][-m MSTR][--baseline[=FILE]][--render NAME][-width N][-ruler R]
+         [-bits B][-endian E] file.go [...]
+  bplint baseline write [-m MSTR][--baseline=FILE] file.go [...]
+  bplint find [-w][-min SCORE] file.go [...]
+  bplint gen LANG -t TAG [-type NAME][-pkg NAME][-bits B][-endian E][-o FILE] file.go [...]
+  bplint map [-m MSTR][--format FMT][--render NAME][-width N][-ruler R]
+             [-bits B][-endian E][-o OUT] file.go|dir [...]
+  bplint import svd --peripheral NAME [-pkg NAME][-o FILE] file.svd
+  bplint import rfc [-pkg NAME][-o FILE] diagram.txt
+  bplint sync-doc [-check][-q][-m MSTR][-bits B][-endian E] file.go|dir [...]
 
     Options:
    -q      : Supress terminal output. Exits with 1 on any error.
    -m MSTR : Check only picstrings with a tag that contains MSTR.
                     Looks into //bitpeek[:Name[:skip]] comments.
+   --baseline[=FILE] : Report only findings not recorded in FILE
+                    (default .bplint-baseline).
+   --render NAME : Draw maps as utf8, ascii or ansi, see above.
+   -width N : Wrap maps to N columns, 0 for no wrapping.
+   -ruler R : Add byte, or byte and nibble, ruler rows: R is byte or nibble.
+   -bits B : Number bits lsb0 (default) or msb0, see below.
+   -endian E : Number bytes little (default) or big endian.
+
+
+Options apply to files given after them.
+
+Maps wider than the terminal, or -width N columns, are wrapped at field
+boundaries into stacked segments, each starting with the high bit of
+its first field and ending with the low bit of its last one:
+
+  $ bplint -width 40 -m Example lint/lint_test.go
+
+  --- Pic: "Example" in lint/lint_test.go line 22 ---
+  OK.
+  bits:|63 3b 61|    60|   59|58 11b 48|
+               ^      ^     ^         ^|
+  cmds:¨¨Type:'F¨ 'EXT=¨.ACK=¨ Id:0xFHH¨
+  bits:|47..     32b     ..16|15 16b 0|
+                            ^        ^|
+  cmds:¨ from IPv4.Address32@¨¨¨:D.16@¨
+
+With -ruler byte a row above the map tells which bytes, 7 to 0, each
+field is in; -ruler nibble adds a row of nibbles, 15 to 0. Fields
+that cross a boundary without both starting and ending at one have
+their cell filled with ~:
+
+  $ bplint -ruler nibble -m Example lint/lint_test.go
+
+  --- Pic: "Example" in lint/lint_test.go line 22 ---------------------
+  OK.
+  byte:|7       |7     |7    |7..6~~~~~|5..2                 |1..0    |
+  nibl:|15      |15    |14   |14..12~~~|11..4                |3..0    |
+  bits:|63 3b 61|    60|   59|58 11b 48|47..     32b     ..16|15 16b 0|
+               ^      ^     ^         ^                     ^        ^|
+  cmds:¨¨Type:'F¨ 'EXT=¨.ACK=¨ Id:0xFHH¨ from IPv4.Address32@¨¨¨:D.16@¨
+
+Bits are numbered LSB0, from the least significant one, bytes and
+nibbles little endian. With -bits msb0 bit 0 is the most significant
+one and with -endian big byte 0 is, as network specs have it. The
+numbering goes to maps, rulers, tables, diagrams, comments of the
+generated code and to findings, RFC diagrams are MSB0 unless -bits
+lsb0 is given (each row then gets a ruler of its own bits, 63..32 over
+31..0). Fields, masks and shifts stay as they are:
+
+  $ bplint -bits msb0 -endian big -ruler byte -m Example lint/lint_test.go
+
+  --- Pic: "Example" in lint/lint_test.go line 22 ---------------------------
+  OK.
+  byte:|0         |0     |0    |0..1~~~~~~~~|2..5                 |6..7     |
+  bits:|0.. 3b ..2|     3|    4|5.. 11b ..15|16..     32b     ..47|48 16b 63|
+                 ^      ^     ^            ^                     ^         ^|
+  cmds:¨¨¨¨Type:'F¨ 'EXT=¨.ACK=¨¨¨¨ Id:0xFHH¨ from IPv4.Address32@¨¨¨¨:D.16@¨
 
 
 Marking picstrings
@@ -45,13 +114,54 @@ special comment line put above the picstring itself:
 Optional ":tag" field is used to match with linter's -m option.
 Picstring tags need not to be unique.
 
-Optional ":skip" number tells linter to skip a few (up to 7) next strings.
+Optional ":skip" digit tells linter to skip a few (up to 7) next strings.
 It helps where the picstring in the source is a part of a longer literal:
 
-		//bitpeek:sometag:1
-		{`Example`, `Type:'F 'EXT=.ACK= Id:0xFHH from IPv4.Address32@:D.16@`},
+    //bitpeek:sometag:1
+    {`Example`, `Type:'F 'EXT=.ACK= Id:0xFHH from IPv4.Address32@:D.16@`},
+
+    // :1 skips string `Example`
+
+After the tag a marker may carry space separated attributes:
+
+    //bitpeek:tag skip=12 width=32 field=Pic nolint=BP002 lang=pl
+
+    skip=N    : skip N next strings, same as :N for N up to 7
+    width=N   : picstring may take at most N bits (default 64)
+    field=Key : take the Key: value of the literal that follows, or
+                argument named Key of the following call; field=N
+                takes the Nth element or argument. Skip then counts
+                strings within that value. Of literals and calls
+                nested on a line the innermost having Key is taken.
+    nolint    : see 'Suppressing findings' below
+    lang=xx   : language of labels
+    masks=Pat : check fields against Mask/Shift constants of the
+                package whose names match Pat, see BP009 below
+
+Old bplint took only the first digit of ":skip", so ":12" skipped one
+string. Such a ":skip" of more digits, or of 8 or 9, is now reported
+as BP005 rather than read in a new way; write skip=12 (or :1) instead.
 
-		// :1 skips string `Example`
+Malformed markers, eg. with unknown attribute or bad number, are
+reported as BP005 errors. So are markers that found no string (BP006),
+ones whose skip ran past the closing brace of the literal or past the
+end of statement (BP007), and look-alikes such as "// bitpeek:" or
+"//bitpeak:" (BP008). Markers left out by -m are counted in a summary.
+
+Whole tables of picstrings can be marked once with a region put
+inside the table literal:
+
+    var lintTests = []struct{ desc, pic string }{
+        //bitpeek:begin tests field=2
+        {`Example`, `Type:'F 'EXT=.ACK= Id:0xFHH`},
+        {`Octals`, `Good ones: 0EFF and:EFF`},
+        //bitpeek:end
+    }
+
+Every {...} element of the region gets its field=N string (second
+if not given), or the one keyed with field=Key, linted. Element is
+named by its first string: "tests/Octals". A marker put inside the
+region wins over it for the string it points at.
 
 
 Valid Numbers
@@ -92,500 +202,252 @@ escapes or an asterisk:
 
 Now both linter and humans will know you knew what you're doing.
 Lint off* or '' trick work with H mixes too.
-*/
-package main
 
-import (
-	"errors"
-	"fmt"
-	rwid "github.com/mattn/go-runewidth"
-	"os"
-	"strings"
-	ts "text/scanner"
-)
 
-// globals, its a cli tool
-var files, seen, errcnt int
-var match string
-var quiet bool
+Rules
 
-func main() {
-	if len(os.Args) == 1 {
-		usage()
-	}
-	fwd := true
-	for i, v := range os.Args { // 'flag' is such a mess ;)
-		switch {
-		case fwd:
-			fwd = false
-			continue
-		case v == `-q`:
-			quiet = true
-		case v == `-m` && i < len(os.Args)-1:
-			match = os.Args[i+1]
-			fwd = true
-		case v == `-h`:
-			usage()
-		default:
-			lintFile(os.Args[i])
-		}
-	}
-	if quiet && (errcnt > 0 || seen == 0 || files == 0) {
-		os.Exit(1)
-	}
-	if files == 0 {
-		prErr(`Error: no files given and/or no files checked!`, quiet)
-		usage()
-	}
-	if seen == 0 {
-		prErr(`Error: no matching picstrings found!`, quiet)
-	}
-	return
-}
-func lintFile(fn string) {
-	fh, err := os.Open(fn)
-	if err != nil {
-		prErr(fmt.Sprintf("Can not %s", err), quiet)
-		errcnt++
-		return
-	}
-	defer fh.Close()
-	files++
-	var f ts.Scanner
-	f.Init(fh)
-	f.Filename = fn
-	f.Mode = ts.ScanRawStrings | ts.ScanStrings | ts.ScanComments
-	skip := -1 // raw strings below to skip
-	picname := `unnamed`
-	for x := f.Scan(); x != ts.EOF; x = f.Scan() {
-		switch {
-		case x == ts.Comment: // bitpeek:name:pos
-			t := strings.Split(f.TokenText(), ":") // valid: //bitpeek:name:skip
-			if t[0] != `//bitpeek` {               // [0] is at least ':'
-				continue
-			}
-			if len(match) > 0 && len(t) > 1 &&
-				strings.Index(t[1], match) < 0 {
-				continue
-			}
-			if len(t) > 2 && len(t[1]) > 0 {
-				picname = t[1]
-			}
-			if len(t) > 2 && t[2][0]|7 == 0x37 { // max skip: 7
-				skip = int(t[2][0] - 48)
-			} else {
-				skip = 0
-			}
-		case skip < 0: // not ours at all
-		case x == ts.String && skip > 0:
-			skip--
-		case x == ts.RawString && skip > 0:
-			skip--
-		case x == ts.RawString || x == ts.String:
-			l := 0
-			s := f.TokenText()
-			r := Lint(s[1 : len(s)-1])
-			if r[0] != `OK.` {
-				errcnt++
-			}
-			seen++
-			skip = -1
-			if quiet {
-				continue
-			}
-			p := f.Pos()
-			d := fmt.Sprintf("--- Pic: \"%s\" in %s line %d -",
-				picname, p.Filename, p.Line)
-			for _, v := range r {
-				ll := rwid.StringWidth(v)
-				if l < ll {
-					l = ll
-				}
-			}
-			if len(d) < l {
-				l = l - len(d)
-			} else {
-				l = 2
-			}
-			fmt.Printf("%s%s\n%s\n%s\n%s\n%s\n\n",
-				d, lFill('-', l),
-				r[0], r[1], r[2], r[3])
-			picname = `unnamed`
-		}
-	}
-	return
-}
-func Lint(pic string) (o [4]string) {
-	var e0 strings.Builder // error, if any
-	var o1 strings.Builder // |  b28..b27 | b26..  4b ..b24 | b23 | b22..b20 |
-	var o2 strings.Builder //           ^                 ^     ^          ^
-	var o3 strings.Builder //        Ac:E           Press:H  'CS= ````Stat:F
-
-	rp, err := ckPicStr(pic)
-	if err != nil {
-		fmt.Fprintf(&e0, "Error: %s", err)
-	} else {
-		fmt.Fprintf(&e0, "OK.")
-	}
-	for _, r := range rp {
-		fmt.Fprintf(&o1, "%s", r.bits)
-		fmt.Fprintf(&o2, "%s", r.mark)
-		fmt.Fprintf(&o3, "%s", r.pics)
-	}
-	o[0] = e0.String()
-	o[1] = o1.String()
-	o[2] = o2.String()
-	o[3] = o3.String()
-	return
-}
+Every finding comes from a rule with an ID:
 
-type part struct {
-	bits string
-	mark string
-	pics string
-}
+  BP000 - syntax errors of dd@ commands
+  BP001 - pic string takes more than 64 bits
+  BP002 - bad shape of a hex number
+  BP003 - misleading use of B/E/F numbers
+  BP004 - nolint that suppressed nothing
+  BP005 - malformed marker
+  BP006 - marker matched no picstring
+  BP007 - marker skip ran past the literal or statement end
+  BP008 - misspelled marker, eg. // bitpeek: or //bitpeak:
+  BP009 - field bits differ from its masks= constants
 
-func ckPicStr(inp string) (rp []part, err error) {
-	pic := "?" + inp + " " // simplify for loop output
-	pi := len(pic) - 1     // pic index
-	var bi uint16          // bit index, not more than 64
-	var quoted, label bool // flow control
-	rp = make([]part, 256) // be generous
-
-	ri := len(rp) - 1 // output (part) index
-	rp[ri].bits = `|` // close output
-	rp[ri].mark = `|`
-	ri--
-
-	curbitstart := bi   // ...from bit
-	curpicend := pi - 1 // ...picture end
-	prevcmd := 'T'      // picstring tail
-	for pi > 0 && ri >= 0 {
-		pi--
-		w := pic[pi]
-		switch {
-		case pi == 0:
-			w = '?'
-		case pi > 0 && pic[pi-1] == '\\':
-			pi--
-			continue
-		case w == '\'' && (label || quoted):
-			label = false
-			quoted = false
-			continue
-		case w == '\'':
-			quoted = true
-			continue
-		case quoted && w != '\'':
-			continue
-		case label && w|3 != 63:
-			continue
-		case w|3 == 63:
-			label = true
-		case w == 'D' || w < 0x3c || w > 0x48:
-			continue
-		}
-		if prevcmd == 'T' { // output the tail
-			rp[ri].pics = fmt.Sprintf("%s", pic[pi+1:curpicend+1])
-			ri--
-		} else { // output previous part
-			var b strings.Builder // |  b28..b27 | b26..  4b ..b24 | b23 | b22..b20 |
-			var m strings.Builder //           ^                 ^     ^          ^
-			var s strings.Builder //        Ac:E           Press:H  'CS= ````Stat:F
-
-			lenB := bi - curbitstart // field bitlength
-			lenC := rwid.StringWidth(pic[pi:curpicend])
-			lenC += 1 // add for separator
-
-			// bitdesc
-			var bDesc string
-			if lenB == 1 {
-				b.Reset()
-				fmt.Fprintf(&b, "|%d", curbitstart) // single bit
-				bDesc = b.String()
-				if lenC > len(bDesc) { // adjust
-					adj := lenC - len(bDesc)
-					b.Reset()
-					fmt.Fprintf(&b, "|")
-					for i := adj; i > 0; i-- {
-						//b.WriteByte('.') // adjust left
-						b.WriteByte(' ') // adjust left
-					}
-					fmt.Fprintf(&b, "%d", curbitstart)
-					bDesc = b.String()
-				}
-			}
-			if lenB > 1 { // output previous part
-				b.Reset()
-				fmt.Fprintf(&b, "|%d %db %d", bi-1, lenB, curbitstart)
-				bdMid := b.String()
-				b.Reset()
-				fmt.Fprintf(&b, "|%d.. %db ..%d", bi-1, lenB, curbitstart)
-				bdLong := b.String()
-				b.Reset()
-				switch {
-				default:
-					panic("May not happen!")
-				case lenC <= len(bdMid): // short
-					bDesc = bdMid
-				case lenC <= len(bdLong): // long
-					bDesc = bdLong
-				case lenC > len(bdLong): // adjust desc to fit pic
-					adj := lenC - len(bdLong)
-					b.Reset()
-					fmt.Fprintf(&b, "|%d.. ", bi-1)
-					for i := adj - adj/2; i > 0; i-- {
-						//b.WriteByte('<') // adjust left
-						b.WriteByte(' ') // adjust left
-					}
-					fmt.Fprintf(&b, "%db", lenB)
-					for i := adj / 2; i > 0; i-- {
-						//b.WriteByte('>') // adjust right
-						b.WriteByte(' ') // adjust right
-					}
-					fmt.Fprintf(&b, " ..%d", curbitstart)
-					bDesc = b.String()
-				}
-			}
-			s.Reset()
-			if lenC > 0 {
-				for i := lenC; i < len(bDesc); i++ {
-					s.WriteRune('¨') // mark our inserts with diaresis
-				}
-				fmt.Fprintf(&s, "%s¨", pic[pi+1:curpicend+1])
-			}
-			if lenB > 0 { // make marker
-				m.Reset()
-				m.WriteByte(' ')
-				for i := len(bDesc) - 2; i > 0; i-- {
-					m.WriteByte(' ')
-				}
-				m.WriteByte('^')
-			}
-			rp[ri].bits = bDesc
-			rp[ri].mark = m.String()
-			rp[ri].pics = s.String()
-			ri--
-		} // output previous part
-		if pi <= 0 {
-			break
-		}
-
-		curpicend = pi
-		curbitstart = bi
-		switch {
-		case w|3 == 63 || w == 'B': // single bits - bbChain
-			prevcmd = 'L'
-			bi++
-			if pi > 0 && w == 'B' { // check for glued A..H
-				nn := pic[pi-1]
-				if nn != 'B' && !(nn < 49 || nn|1 == 0x3b || nn > 72) {
-					err = errors.New(
-						"Misleading use of B/E/F number. See section 'Valid Numbers' in docs.")
-					break
-				}
-			}
-		case w == '@': // varbits, Number
-			prevcmd = 'N'
-			pi, bi, err = ckVarblen(pic, pi, bi)
-		default: // ACEFGH - bbRange
-			prevcmd = 'N' // number
-			pi, bi, err = ckRanges(pic, pi, bi)
-		}
-		if err != nil {
-			var sp strings.Builder
-			for i := range pic[:curpicend] {
-				if i >= pi-2 { // -2: show at least boundary
-					sp.WriteByte('^')
-				} else {
-					sp.WriteByte(' ')
-				}
-			}
-			ri = len(rp) - 1
-			rp[ri].bits = ``
-			rp[ri].pics = sp.String() + `HERE`
-			rp[ri].mark = pic[1:]
-			return rp[ri:], err
-		}
-	}
-	rp[ri].bits = `bits:`
-	rp[ri].mark = `     `
-	rp[ri].pics = `cmds:¨`
-	if bi > 64 {
-		rp[ri].bits = ` ERR:`
-		err = errors.New(
-			"Pic string takes more than 64 bits!")
-	}
-	return rp[ri:], err
-}
+Team specific checks can be written as lint.Rule and registered
+in a small main package of your own, that then calls lint.Main.
+See docs of the github.com/ohir/bplint/lint package.
 
-func ckVarblen(pic string, pi int, bi uint16) (int, uint16, error) {
-	if pi < 3 { // !dd@
-		return 0, bi, errors.New("Misplaced @")
-	}
-	k := (10 * uint8(pic[pi-2]-48)) + uint8(pic[pi-1]-48)
-	var d = 4
-	if k > 16 {
-		d = int(k / 3)
-	}
-	switch {
-	case k == 0, k > 64:
-		return pi - 2, bi, errors.New("Bad bitcount.")
-	case pi > 2 && pic[pi-3] == '!': // !dd@ skip dd bits
-		pi -= 3
-		bi += uint16(k)
-	case pi > d-1 && pic[pi-d] == 'D': // D.dd@ Decimal
-		pi -= d
-		bi += uint16(k)
-	case pi > 13 && pic[pi-14] == 'I': // I##.###.###.32@ is not now allowed
-		pi -= 14
-		bi += 32
-		if pic[pi:pi+15] != `IPv4.Address32@` { // force it
-			return pi, bi, errors.New("Invalid pic for IPv4.")
-		}
-	default:
-		return pi - 2, bi, errors.New("Can't find valid start command for this dd@.")
-	}
-	return pi, bi, nil
-}
+With masks=Hdr* the package of the file is type checked and constants
+HdrIdMask and HdrIdShift are taken for the field named Id (see field
+names under 'Generating code'). A mask may be in place (0x7ff << 48) or
+unshifted (0x7ff) when a Shift is given. Each field must take exactly
+the bits of its constants, mismatch is reported with both bit ranges:
 
-//
-// Valid Numbers
-//
-// before series of H can come a *single* completing digit of F, E or B (giving valid hex number)
-// before series of F can come a *single* completing digit of E or B (giving valid octal number)
-// any consecutive mix of EFH is not allowed unless escaped with a '*' marker after the offending
-// sequence of commands. See _test file.
-// chain of Bs is allowed
-func ckRanges(pic string, pi int, bi uint16) (int, uint16, error) {
-	var c byte
-	w := pic[pi]
-	if pi > 0 {
-		c = pic[pi-1]
-	}
-	switch w {
-	case 'H': // HHH FHH EHH BHH
-	nextH:
-		bi += 4
-		if pi > 0 && pic[pi-1] == 'H' {
-			pi--
-			goto nextH
-		}
-		if pi > 0 { // only single B|E|F allowed after H's
-			c = pic[pi-1]
-			switch c {
-			case 'F':
-				bi += 3
-			case 'E':
-				bi += 2
-			case 'B':
-				bi += 1
-			default:
-				pi++ // no match - negate -- below
-			}
-			pi--
-		}
-		if pi > 0 { // check for wrongs, no hex continuation can be glued after FEB
-			c = pic[pi-1]
-			if c == 'H' ||
-				c == 'F' ||
-				c == 'E' ||
-				c == 'B' {
-				return pi, bi, errors.New(
-					"Bad shape of a Hex number. See section 'Valid Numbers' in docs.")
-				// Eg. BHH 9b, EHH 10b, FHH 11b, HHH 12b, BHHH 13b and so on.
-			}
-		}
-	case 'C':
-		bi += 8
-	case 'A':
-		bi += 7
-	case 'G':
-		bi += 5
-	case 'F': // FFF EFF BFF
-		var off, n, nn byte // for '*' switch, nExt char
-		var oct bool
-		efs := 1
-		if pi < len(pic)-1 {
-			off = pic[pi+1]
-		}
-	nextF:
-		bi += 3
-		if pi > 0 && pic[pi-1] == 'F' {
-			efs++
-			pi--
-			goto nextF
-		}
-		if efs == 2 && pi > 0 && pic[pi-1] == 'E' {
-			bi += 2
-			pi--
-			oct = true // valid octal shape
-		}
-		// checks
-		if pi > 0 {
-			nn = pic[pi-1]
-		}
-		switch {
-		case off == '*':
-			// OK. checks turned off
-		case efs == 1 && (n < 65 || n > 72):
-			// ok, lone F, no A-F commands in front of
-		case oct && (nn < 49 || nn|1 == 0x3b || nn > 72):
-			// ok, Octal prepended by chr <= '0', :;, chr >= 'I'
-		default:
-			return pi, bi, errors.New(
-				"Misleading use of B/E/F number. See section 'Valid Numbers' in docs.")
-		}
-	case 'E': // separate entity
-		var off, n byte // for '*' switch, nExt char
-		efs := 1
-	nextE:
-		bi += 2
-		if pi > 0 && pic[pi-1] == 'E' {
-			efs++
-			pi--
-			goto nextE
-		}
-		if pi < len(pic)-1 {
-			off = pic[pi+1]
-		}
-		if pi > 0 {
-			n = pic[pi-1]
-		}
-		switch {
-		case off == '*':
-			// OK. checks turned off
-		case efs == 1 && pi == 0:
-			// ok, allow for opening single E
-		case efs == 1 && (n < 65 || n > 72):
-			// ok, no direct command ahead
-		default:
-			return pi, bi, errors.New(
-				"Misleading use of B/E/F number. See section 'Valid Numbers' in docs.")
-		}
-	default:
-	}
-	return pi, bi, nil
-}
-func usage() {
-	fmt.Printf("%s\nUsage: %s [options] file [file...]\n"+
-		"\n    Options:\n\n"+
-		"   -q      : Suppress terminal output.  Exit with 1 on any error.\n"+
-		"   -m MSTR : Check only picstrings with a tag that contains MSTR.\n"+
-		"                      Looks into //bitpeek[:tag[:skip]] comments.\n\n",
-		lFill('_', len(fmt.Sprintf("Usage: %s [options] file [file...]", os.Args[0]))),
-		os.Args[0])
-	os.Exit(0)
-}
-func prErr(s string, q bool) {
-	if !q {
-		fmt.Fprintf(os.Stderr, "%s\n%s\n", lFill('_', len(s)), s)
-	}
-}
-func lFill(c byte, n int) (r []byte) {
-	r = make([]byte, n)
-	for i := range r {
-		r[i] = c
-	}
-	return
+  Error: Field Id takes bits 58..48, HdrIdMask has bits 57..47. [BP009]
+
+
+Suppressing findings
+
+Rules can be silenced without touching the picstring itself. A nolint
+put on the marker applies to that one picstring. Either for all rules
+or for listed ones:
+
+  //bitpeek:tag:1:nolint
+  //bitpeek:tag:1:nolint=BP002,BP003
+
+A golangci style //nolint:bplint (or //nolint:BP002,...) comment works
+for the picstring of the marker right below it, or in between the marker
+and its picstring. If put above the package clause it applies to the
+whole file. Other linters' names in the list are ignored.
+
+Nolint that suppressed nothing is reported as BP004, so stale ones
+do not pile up. So is one that reaches no marker: trails code, or has
+code between it and the next marker. A bare //nolint is left alone then,
+it may be for other linters.
+
+
+Baseline
+
+In a tree with many existing findings CI can be made to fail only
+on new ones. First record what is there now:
+
+  $ bplint baseline write ./pkg/*.go
+
+Then check against the record:
+
+  $ bplint check --baseline ./pkg/*.go
+
+Findings are keyed by file, tag, picstring hash and rule. Files are
+keyed by clean paths relative to the working directory, so ./a.go, a.go
+and $PWD/a.go match the same entries. Entries of checked files that are
+no longer found are reported as fixed, so the baseline can be rewritten
+to shrink. Baseline write rewrites only entries of the files given, and
+with -m only those of matching tags; the rest of the baseline is kept.
+Check with -m reports as fixed only entries of matching tags.
+
+
+Finding unmarked picstrings
+
+The find command audits all string literals of given files and scores
+how likely each unmarked one is a picstring. Signals are dd@ commands,
+IPv4.Address32@, runs of H, 'FLAG= patterns and whether the string
+passes checks cleanly; fmt verbs count against. For each candidate
+scoring -min or more (default 50) a marker is suggested, with -w it
+is inserted into the file above the candidate's line. Its tag is the
+name the string is assigned to, declared as or keyed by (x := ...,
+const X = ..., Key: ...), else the first string of the line; tags begin
+and end get a trailing _ not to mark a region. Strings before the
+candidate on its line are skipped with skip=N:
+
+  $ bplint find -min 60 -w ./pkg/*.go
+
+
+Rendering maps
+
+The map command renders marked picstrings that check clean, findings of
+others are printed instead. With --format bits (default) it is the map
+check shows; --format rfc draws an RFC style packet diagram, 32 bits
+per row with the most significant bit first and numbered 0. Names too
+long for their box go to footnotes, skips are shown as Reserved and bits
+above the last field, up to the marker's width, as Unused:
+
+  $ bplint map --format rfc -m Example lint/lint_test.go
+
+   0                   1                   2                   3
+   0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+  |Type |1|2|         Id          |            Addr16             |
+  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+  |            Addr16             |             Dec0              |
+  +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+    1: EXT
+    2: ACK
+
+With --format vertical fields are listed a line each, from the most
+significant bit, which is easier to review for long picstrings. Bits
+above the last field, up to the marker's width, are shown as unused
+and !dd@ skips as skipped:
+
+  $ bplint map --format vertical -m status lint/testdata/gen.go
+
+  bits    width  kind  picture   label
+  39..32      8  -               (unused)
+  31..29      3  num   Type:'F   Type
+  28          1  flag  'EXT=     EXT
+  ...
+  15..8       8  skip  !08@      (skipped)
+  7..0        8  hex   Len:HH    Len
+
+With --format svg each picstring is drawn as a register diagram: boxes
+as wide as fields, bit numbers at their edges, colored by kind of the
+field (flag, hex, octal, decimal, IPv4, skip). With -o DIR every
+diagram goes to its own .svg file in DIR.
+
+With --format html a single report is made, to -o FILE or stdout: an
+index of all marked picstrings with links to their source lines, then
+the picstring and its diagram, or its findings. Directories given are
+walked for go files, hidden, vendor and testdata ones excepted:
+
+  $ bplint map --format html -o layouts.html .
+
+With --format md, csv or adoc each picstring is a table of its fields:
+name, kind, bits (hi..lo), width, mask, shift and the picture. Paste
+it into Markdown, a spreadsheet or an AsciiDoc datasheet:
+
+  $ bplint map --format md -m Example lint/lint_test.go
+
+  | Name | Kind | Bits | Width | Mask | Shift | Picture |
+  |---|---|---|---|---|---|---|
+  | Type | num | 63..61 | 3 | 0xe000000000000000 | 61 | `` Type:'F `` |
+  | EXT | flag | 60..60 | 1 | 0x1000000000000000 | 60 | `` 'EXT= `` |
+  ...
+
+
+Keeping maps in comments
+
+The sync-doc command writes the bits map of every marked picstring
+into a comment block right above its marker, or refreshes the block
+if it is already there. Blocks are made so gofmt leaves them alone:
+
+  $ bplint sync-doc ./wire
+
+  // bplint:map
+  //
+  //	bits:|11 11b 1|     0|
+  //	             ^      ^|
+  //	cmds:¨Id:0xFHH¨ 'ACK=¨
+  //
+  // bplint:end
+  //
+  //bitpeek:Hdr width=16
+  Hdr = `Id:0xFHH 'ACK=`
+
+With -check nothing is written, markers with stale or missing blocks
+are listed and bplint exits with 1, so CI can catch a picstring
+changed without its map. Picstrings that do not check clean get their
+findings printed and their blocks are left as they were. Markers put
+after code on the same line get no block.
+
+
+Generating code
+
+The gen command makes code for a picstring that checks clean. The
+picstring is picked by its exact tag:
+
+  $ bplint gen go -t Example -type Hdr -pkg wire -o hdr_gen.go lint/lint_test.go
+
+For go it makes a named type sized to the declared width, Shift and Mask
+constants for every field, getters and setters (flags get bools), and
+a String method calling bitpeek.Pic. Skips (!dd@) get nothing. Type name
+defaults to the tag, package to the one of the source file.
+
+For c it makes a header with NAME_SHIFT, NAME_MASK, NAME_GET(x) and
+NAME_SET(x, v) macros for every field, prefixed with the type name in
+upper case, eg. HDR_ID_MASK. The header comment holds the picstring
+and its bit map, with | put for ¨.
+
+For sv it makes a SystemVerilog typedef struct packed, most significant
+field first, with logic [N:0] members named in lower snake case. Skips
+become reservedN members, bits above the last field up to the declared
+width (marker's width=) a pad member.
+
+For ksy it makes a Kaitai Struct spec: a seq of b<N> fields read in
+big-endian bit order, decimals and IPv4 addresses that sit on a byte
+boundary as u1, u2, u4 or u8. For wavedrom it makes WaveDrom bitfield
+(reg) JSON with field names and bit counts; flags are colored with type
+2, numbers with type 4 and skips with type 1.
+
+For wireshark it makes a Lua dissector with one ProtoField per field,
+masked within the picstring word read big-endian from the start of the
+buffer: bool for flags named by their labels, uint for numbers in hex,
+oct or dec base, and ipv4 for byte aligned IPv4 addresses.
+
+
+Field names come from labels: a flag is named by its label ('EXT= gives
+EXT), other fields by the word put right before the command and ':', '='
+or '#' (Id in "Id:0xFHH", Type in "Type:'F") or quoted text put there
+('Type of Service':HH gives TypeOfService). Fields without such a name
+are named by their kind and lowest bit, eg. Hex32, Dec0 or Addr16.
+Repeated names get _2, _3 suffixes.
+
+
+Importing layouts
+
+The import command goes the other way: it makes picstrings of layouts
+described elsewhere and prints them as a go file of marked constants.
+Each picstring is checked before it is printed, ones that would not
+lint clean are reported instead.
+
+  $ bplint import svd --peripheral UART0 -o uart0.go vendor.svd
+
+For svd (CMSIS-SVD XML) every register of the peripheral, derived ones
+too, gets a picstring: flags for single bit fields, hex numbers in
+valid shape for wider ones, !dd@ skips for gaps between and below the
+fields. Register size goes into the marker's width=:
+
+  //bitpeek:UART0/CR width=32
+  UART0CR = `'BAUD':HHH !06@ 'RXE= 'TXE= !07@ 'UARTEN=`
+
+For rfc every +-+-+ packet diagram of the text file, of one or two 32
+bit rows, gets a picstring named by the file (ipv4.txt gives ipv4,
+ipv4_2 ...). Box edges set field widths, box text their names; boxes
+named Reserved, Unused, MBZ or left empty become skips. Boxes that can
+not be read, eg. with an edge off the bit boundary, are reported with
+their line and column:
+
+  Can not import: hdr.txt:5:13: box edge is not on a bit boundary
+*/
+package main
+
+import "github.com/ohir/bplint/lint"
+
+func main() {
+	lint.Main()
 }
@@ -0,0 +1,135 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// baseline is a set of known findings. Each is keyed by file, tag,
+// picstring hash and rule, tab separated. Same key may repeat.
+type baseline struct {
+	fn      string
+	ents    map[string]int  // known, not yet met
+	checked map[string]bool // files looked at
+	found   []string        // keys met, for write
+	wr      bool            // baseline write mode
+}
+
+const baseHdr = "# bplint baseline: file, tag, picstring hash, rule\n"
+
+func baseKey(fn, tag, pic, rule string) string {
+	h := fnv.New64a()
+	h.Write([]byte(pic))
+	return fmt.Sprintf("%s\t%s\t%016x\t%s", basePath(fn), tag, h.Sum64(), rule)
+}
+
+// basePath returns fn as it is keyed: cleaned, with slashes, and relative
+// to the working directory if it is below it. So ./a.go, a.go and
+// /abs/path/a.go all make the same key.
+func basePath(fn string) string {
+	if filepath.IsAbs(fn) {
+		if wd, err := os.Getwd(); err == nil {
+			if r, err := filepath.Rel(wd, fn); err == nil && !strings.HasPrefix(r+`/`, `../`) {
+				fn = r
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(fn))
+}
+
+// loadBaseline reads baseline file fn. In write mode file is not read,
+// it is to be overwritten, see write.
+func loadBaseline(fn string, write bool) (*baseline, error) {
+	b := &baseline{fn: fn, ents: map[string]int{}, checked: map[string]bool{}, wr: write}
+	if write {
+		return b, nil
+	}
+	fh, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	sc := bufio.NewScanner(fh)
+	for sc.Scan() {
+		t := sc.Text()
+		if len(t) == 0 || t[0] == '#' {
+			continue
+		}
+		if strings.Count(t, "\t") != 3 {
+			return nil, fmt.Errorf("%s: malformed line: %q", fn, t)
+		}
+		b.ents[t]++
+	}
+	return b, sc.Err()
+}
+
+// known tells whether a finding is in the baseline, each entry
+// covers a single finding.
+func (b *baseline) known(fn, tag, pic, rule string) bool {
+	k := baseKey(fn, tag, pic, rule)
+	b.found = append(b.found, k)
+	if b.ents[k] > 0 {
+		b.ents[k]--
+		return true
+	}
+	return false
+}
+
+// stale returns entries the run owns that were not met.
+func (b *baseline) stale() (r []string) {
+	for k, n := range b.ents {
+		if !b.owns(k) {
+			continue
+		}
+		for ; n > 0; n-- {
+			r = append(r, k)
+		}
+	}
+	sort.Strings(r)
+	return
+}
+
+// owns tells whether entry k is rewritten by this run: it is of a checked
+// file and of a tag matching -m. Findings with no tag are not filtered by
+// -m, neither are elements of a region whose tag matches.
+func (b *baseline) owns(k string) bool {
+	fs := strings.SplitN(k, "\t", 3)
+	tag, _, _ := strings.Cut(fs[1], `/`)
+	return b.checked[fs[0]] && (len(fs[1]) == 0 || strings.Contains(tag, match))
+}
+
+// write stores findings met to the baseline file. Entries the run did
+// not own, of other files or of tags not matching -m, are kept from the
+// file as it was.
+func (b *baseline) write() error {
+	found := b.found
+	old, err := loadBaseline(b.fn, false)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return err
+	default:
+		for k, n := range old.ents {
+			for ; n > 0 && !b.owns(k); n-- {
+				found = append(found, k)
+			}
+		}
+	}
+	sort.Strings(found)
+	var w strings.Builder
+	w.WriteString(baseHdr)
+	for _, v := range found {
+		w.WriteString(v)
+		w.WriteByte('\n')
+	}
+	return os.WriteFile(b.fn, []byte(w.String()), 0644)
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseline(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), `base`)
+	b, _ := loadBaseline(fn, true)
+	b.known(`a.go`, `x`, `FFF`, `BP003`)
+	b.known(`a.go`, `x`, `FFF`, `BP003`) // same twice
+	b.known(`a.go`, `y`, `EFHH`, `BP002`)
+	b.known(`b.go`, `z`, `EFHH`, `BP002`)
+	if err := b.write(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := loadBaseline(fn, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.checked[basePath(`./a.go`)] = true // b.go not looked at
+	if !b.known(`a.go`, `x`, `FFF`, `BP003`) {
+		t.Error("known finding reported as new")
+	}
+	if b.known(`a.go`, `x`, `FF F`, `BP003`) {
+		t.Error("changed picstring taken as known")
+	}
+	if !b.known(`./a.go`, `y`, `EFHH`, `BP002`) {
+		t.Error("./a.go not taken as a.go")
+	}
+	st := b.stale()
+	if len(st) != 1 || st[0] != baseKey(`a.go`, `x`, `FFF`, `BP003`) {
+		t.Errorf("bad stale entries: %q", st)
+	}
+}
+
+// Write with -m keeps entries of other tags and of files not checked.
+func TestBaselineMatch(t *testing.T) {
+	defer func(m string) { match = m }(match)
+	fn := filepath.Join(t.TempDir(), `base`)
+	b, _ := loadBaseline(fn, true)
+	b.known(`a.go`, `x`, `FFF`, `BP003`)
+	b.known(`a.go`, `y`, `EFHH`, `BP002`)
+	b.known(`b.go`, `y`, `EFHH`, `BP002`)
+	b.write()
+	match = `y`
+	b, _ = loadBaseline(fn, true)
+	b.checked[`a.go`] = true
+	b.known(`a.go`, `y`, `HHH`, `BP002`)
+	if err := b.write(); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = loadBaseline(fn, false)
+	for _, k := range []string{baseKey(`a.go`, `x`, `FFF`, `BP003`), baseKey(`a.go`, `y`, `HHH`, `BP002`),
+		baseKey(`b.go`, `y`, `EFHH`, `BP002`)} {
+		if b.ents[k] != 1 {
+			t.Errorf("entry %q: %d, expected 1", k, b.ents[k])
+		}
+	}
+	if len(b.ents) != 3 {
+		t.Errorf("replaced entry kept: %v", b.ents)
+	}
+}
+
+// Write of some files keeps entries of the others, check with -m does
+// not take entries of other tags as fixed.
+func TestBaselineKeep(t *testing.T) {
+	defer func(m string) { match = m }(match)
+	match = ``
+	fn := filepath.Join(t.TempDir(), `base`)
+	b, _ := loadBaseline(fn, true)
+	b.known(`a.go`, `foo`, `EFHH`, `BP002`)
+	b.known(`a.go`, `bar`, `FFF`, `BP003`)
+	b.known(`b.go`, `foo`, `EFHH`, `BP002`)
+	b.write()
+	b, _ = loadBaseline(fn, true)
+	b.checked[`a.go`] = true
+	b.known(`a.go`, `foo`, `EFHH`, `BP002`)
+	b.write()
+	b, _ = loadBaseline(fn, false)
+	if len(b.ents) != 2 || b.ents[baseKey(`b.go`, `foo`, `EFHH`, `BP002`)] != 1 {
+		t.Errorf("entries of b.go not kept: %v", b.ents)
+	}
+	match = `foo`
+	b, _ = loadBaseline(fn, false)
+	b.checked[`a.go`] = true
+	b.known(`a.go`, `foo`, `EFHH`, `BP002`)
+	b.ents[baseKey(`a.go`, `bar`, `FFF`, `BP003`)] = 1
+	if st := b.stale(); len(st) != 0 {
+		t.Errorf("entries of tags not matching -m taken as fixed: %q", st)
+	}
+}
+
+func TestBasePath(t *testing.T) {
+	wd, _ := os.Getwd()
+	for _, v := range []string{`a.go`, `./a.go`, `x/../a.go`, filepath.Join(wd, `a.go`)} {
+		if p := basePath(v); p != `a.go` {
+			t.Errorf("%s keyed as %s", v, p)
+		}
+	}
+}
+
+// Unused nolint findings are recorded and then known like any other.
+func TestBaselineNolint(t *testing.T) {
+	defer func(q bool) { base, quiet, errcnt = nil, q, 0 }(quiet)
+	fn := filepath.Join(t.TempDir(), `base`)
+	base, _ = loadBaseline(fn, true)
+	quiet, errcnt = true, 0
+	lintFile(`testdata/nolint.go`)
+	if errcnt == 0 {
+		t.Fatal("no unused nolint found")
+	}
+	if err := base.write(); err != nil {
+		t.Fatal(err)
+	}
+	base, _ = loadBaseline(fn, false)
+	errcnt = 0
+	lintFile(`testdata/nolint.go`)
+	if errcnt != 0 || len(base.stale()) != 0 {
+		t.Errorf("baseline left %d findings, stale %q", errcnt, base.stale())
+	}
+}
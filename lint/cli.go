@@ -0,0 +1,329 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	ts "text/scanner"
+)
+
+// globals, its a cli tool
+var files, seen, errcnt, filtered int
+var match string
+var quiet bool
+var base *baseline // --baseline or baseline write
+var num Numbering  // -bits and -endian, given to check
+
+const baseDefault = `.bplint-baseline`
+
+// Main runs the bplint command.
+func Main() {
+	if len(os.Args) == 1 {
+		usage()
+	}
+	args := os.Args[1:]
+	write := false
+	rnd = autoRenderer()
+	switch {
+	case args[0] == `find`:
+		runFind(args[1:])
+		return
+	case args[0] == `gen`:
+		runGen(args[1:])
+		return
+	case args[0] == `map`:
+		runMap(args[1:])
+		return
+	case args[0] == `import`:
+		runImport(args[1:])
+		return
+	case args[0] == `sync-doc`:
+		runSyncDoc(args[1:])
+		return
+	case args[0] == `check`:
+		args = args[1:]
+	case args[0] == `baseline` && len(args) > 1 && args[1] == `write`:
+		args = args[2:]
+		write = true
+		base, _ = loadBaseline(baseDefault, true)
+	}
+	fwd := false
+	for i, v := range args { // 'flag' is such a mess ;)
+		switch {
+		case fwd:
+			fwd = false
+			continue
+		case v == `-q`:
+			quiet = true
+		case (v == `-bits` || v == `-endian`) && i < len(args)-1:
+			setNum(v, args[i+1])
+			fwd = true
+		case v == `-m` && i < len(args)-1:
+			match = args[i+1]
+			fwd = true
+		case v == `-h`:
+			usage()
+		case v == `--render` && i < len(args)-1:
+			setRender(args[i+1])
+			fwd = true
+		case strings.HasPrefix(v, `--render=`):
+			setRender(strings.TrimPrefix(v, `--render=`))
+		case v == `-width` && i < len(args)-1:
+			setWidth(args[i+1])
+			fwd = true
+		case v == `-ruler` && i < len(args)-1:
+			setRuler(args[i+1])
+			fwd = true
+		case v == `--baseline` || strings.HasPrefix(v, `--baseline=`):
+			fn := strings.TrimPrefix(strings.TrimPrefix(v, `--baseline`), `=`)
+			if len(fn) == 0 {
+				fn = baseDefault
+			}
+			var err error
+			if base, err = loadBaseline(fn, write); err != nil {
+				prErr(fmt.Sprintf("Can not read baseline: %s", err), quiet)
+				os.Exit(1)
+			}
+		default:
+			lintFile(args[i])
+		}
+	}
+	if write {
+		if err := base.write(); err != nil {
+			prErr(fmt.Sprintf("Can not %s", err), false)
+			os.Exit(1)
+		}
+		if !quiet {
+			fmt.Printf("Baseline: %d findings written to %s\n", len(base.found), base.fn)
+		}
+		return
+	}
+	if base != nil {
+		if st := base.stale(); len(st) > 0 && !quiet {
+			fmt.Printf("--- Baseline: %d stale entries in %s -\n", len(st), base.fn)
+			for _, v := range st {
+				fmt.Printf("Fixed: %s\n", strings.Replace(v, "\t", " ", -1))
+			}
+			fmt.Println()
+		}
+	}
+	if filtered > 0 && !quiet {
+		fmt.Printf("--- %d markers not matching -m %q skipped -\n\n", filtered, match)
+	}
+	if quiet && (errcnt > 0 || seen == 0 || files == 0) {
+		os.Exit(1)
+	}
+	if files == 0 {
+		prErr(`Error: no files given and/or no files checked!`, quiet)
+		usage()
+	}
+	if seen == 0 {
+		prErr(`Error: no matching picstrings found!`, quiet)
+	}
+	return
+}
+func lintFile(fn string) {
+	ps, fsup, err := scanFile(fn)
+	if err != nil {
+		prErr(fmt.Sprintf("Can not %s", err), quiet)
+		errcnt++
+		return
+	}
+	files++
+	if base != nil {
+		base.checked[basePath(fn)] = true
+	}
+	for i := range ps {
+		p := &ps[i]
+		if p.bad != nil {
+			if fsup.covers(p.bad.Rule) || base != nil && base.known(fn, p.tag, ``, p.bad.Rule) {
+				continue
+			}
+			errcnt++
+			if !quiet && (base == nil || !base.wr) {
+				what := `Marker`
+				if p.bad.Rule == `BP004` {
+					what = `Nolint`
+				}
+				fmt.Printf("--- %s in %s line %d -\n", what, p.pos.Filename, p.pos.Line)
+				prDiags([]Diagnostic{*p.bad})
+				fmt.Println()
+			}
+			continue
+		}
+		l, ds := checkMarked(fn, p, fsup)
+		var keep []Diagnostic
+		for _, d := range ds {
+			if base == nil || !base.known(fn, p.tag, p.pic, d.Rule) {
+				keep = append(keep, d)
+			}
+		}
+		e0, segs := lintSegs(l, keep, rnd, termWidth(), rulers)
+		if p.last {
+			for _, v := range p.sup.unused() {
+				if base == nil || !base.known(fn, p.tag, p.pic, `BP004`) {
+					keep = append(keep, unusedNolint(v))
+				}
+			}
+		}
+		errcnt += len(keep)
+		seen++
+		if quiet || base != nil && base.wr {
+			continue
+		}
+		prPic(p.tag, p.pos, e0, segs, keep)
+	}
+	var ds []Diagnostic
+	for _, v := range fsup.unused() {
+		if base == nil || !base.known(fn, ``, ``, `BP004`) {
+			ds = append(ds, unusedNolint(v))
+		}
+	}
+	if len(ds) > 0 {
+		errcnt += len(ds)
+		if !quiet && (base == nil || !base.wr) {
+			fmt.Printf("--- File: %s line %d -\n", fn, fsup.pos.Line)
+			prDiags(ds)
+			fmt.Println()
+		}
+	}
+	return
+}
+
+// checkMarked checks picstring p of file fn as its marker tells: with
+// the declared width, against masks= constants, and with findings that
+// nolints of p or of the file cover left out. Check, map and sync-doc
+// all take picstrings through it, so they agree about a source.
+func checkMarked(fn string, p *marked, fsup *suppress) (*Layout, []Diagnostic) {
+	var ms *maskSet
+	if len(p.mk.masks) > 0 {
+		ms = newMaskSet(fn, p.mk.masks)
+	}
+	l, ds := check(p.pic, p.mk.width, ms, num)
+	var keep []Diagnostic
+	for _, d := range ds {
+		if !p.sup.covers(d.Rule) && !fsup.covers(d.Rule) {
+			keep = append(keep, d)
+		}
+	}
+	return l, keep
+}
+
+// BP004: nolint that suppressed nothing
+func unusedNolint(id string) Diagnostic {
+	return Diagnostic{Rule: `BP004`, Msg: fmt.Sprintf("Unused nolint for %s.", id)}
+}
+
+// prPic prints header, lint map segments and then diagnostics not
+// shown on the map.
+func prPic(tag string, p ts.Position, e0 string, segs [][]string, ds []Diagnostic) {
+	l := 0
+	d := fmt.Sprintf("--- Pic: \"%s\" in %s line %d -",
+		tag, p.Filename, p.Line)
+	for _, s := range segs {
+		for _, v := range s {
+			if ll := rnd.width(v); l < ll {
+				l = ll
+			}
+		}
+	}
+	if ll := rnd.width(e0); l < ll {
+		l = ll
+	}
+	if len(d) < l {
+		l = l - len(d)
+	} else {
+		l = 2
+	}
+	if len(ds) > 0 && ds[0].Rule != `BP004` {
+		e0 += fmt.Sprintf(" [%s]", ds[0].Rule)
+		ds = ds[1:]
+	}
+	fmt.Printf("%s%s\n%s\n", d, lFill('-', l), e0)
+	for _, s := range segs {
+		fmt.Println(strings.Join(s, "\n"))
+	}
+	prDiags(ds)
+	fmt.Println()
+}
+
+func prDiags(ds []Diagnostic) {
+	for _, d := range ds {
+		fmt.Printf("Error: %s [%s]\n", d.Msg, d.Rule)
+	}
+}
+func usage() {
+	fmt.Printf("%s\nUsage: %s [check] [options] file [file...]\n"+
+		"       %s baseline write [options] file [file...]\n"+
+		"       %s find [-w][-min SCORE] file [file...]\n"+
+		"       %s gen LANG -t TAG [-type NAME][-pkg NAME][-bits B][-endian E][-o FILE] file [file...]\n"+
+		"       %s map [-m MSTR][--format FMT][--render NAME][-width N][-ruler R][-bits B][-endian E][-o OUT] file|dir [...]\n"+
+		"       %s import svd --peripheral NAME [-pkg NAME][-o FILE] file.svd\n"+
+		"       %s import rfc [-pkg NAME][-o FILE] diagram.txt\n"+
+		"       %s sync-doc [-check][-q][-m MSTR][-bits B][-endian E] file|dir [...]\n"+
+		"\n    Options:\n\n"+
+		"   -q      : Suppress terminal output.  Exit with 1 on any error.\n"+
+		"   -m MSTR : Check only picstrings with a tag that contains MSTR.\n"+
+		"                      Looks into //bitpeek[:tag[:skip]] comments.\n"+
+		"   --baseline[=FILE] : Report only findings not in the baseline\n"+
+		"                      FILE (default "+baseDefault+").\n"+
+		"   -w      : find: insert suggested markers into files.\n"+
+		"   -min N  : find: report candidates scoring N or more (default 50).\n"+
+		"   -t TAG  : gen: make code for the picstring marked TAG.\n"+
+		"                      LANG is go, c, sv, ksy, wavedrom\n"+
+		"                      or wireshark.\n"+
+		"   -type NAME, -pkg NAME : gen: name the type and package made.\n"+
+		"   -o FILE : gen: write to FILE instead of stdout.\n"+
+		"   --format FMT : map: bits, vertical, rfc, svg, html or md, csv,\n"+
+		"                      adoc tables.\n"+
+		"   -check  : sync-doc: report stale map comments, change nothing.\n"+
+		"   --render NAME : check, map: draw maps as utf8, ascii or ansi\n"+
+		"                      (colored). Picked by TERM and NO_COLOR.\n"+
+		"   -width N : check, map: wrap maps to N columns, 0 not to wrap.\n"+
+		"                      Terminal width by default.\n"+
+		"   -ruler R : check, map: add rows telling bytes (R byte), and\n"+
+		"                      nibbles (R nibble), of fields. Straddling\n"+
+		"                      ones get ~.\n"+
+		"   -bits B : number bits from the least (B lsb0, default but for\n"+
+		"                      RFC diagrams) or the most significant one\n"+
+		"                      (B msb0).\n"+
+		"   -endian E : number bytes and nibbles from the least (E little,\n"+
+		"                      default) or the most significant one (E big).\n\n",
+		lFill('_', len(fmt.Sprintf("Usage: %s [check] [options] file [file...]", os.Args[0]))),
+		os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+	os.Exit(0)
+}
+
+// setNum sets num from -bits lsb0|msb0 or -endian little|big.
+func setNum(flag, v string) {
+	switch {
+	case flag == `-bits` && v == `lsb0`:
+		num.Bits = LSB0
+	case flag == `-bits` && v == `msb0`:
+		num.Bits = MSB0
+	case flag == `-endian` && v == `little`:
+		num.Bytes = Little
+	case flag == `-endian` && v == `big`:
+		num.Bytes = Big
+	default:
+		prErr(fmt.Sprintf("Error: bad %s %s, use -bits lsb0|msb0 or -endian little|big", flag, v), false)
+		os.Exit(1)
+	}
+}
+
+func prErr(s string, q bool) {
+	if !q {
+		fmt.Fprintf(os.Stderr, "%s\n%s\n", lFill('_', len(s)), s)
+	}
+}
+func lFill(c byte, n int) (r []byte) {
+	r = make([]byte, n)
+	for i := range r {
+		r[i] = c
+	}
+	return
+}
@@ -0,0 +1,32 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ohir/bplint/lint"
+)
+
+// House rules are made of plain functions then registered before
+// lint.Main is called. Here the rule is run by hand.
+func ExampleNewRule() {
+	upper := lint.NewRule(`HR001`, func(l *lint.Layout) (ds []lint.Diagnostic) {
+		for i := range l.Fields {
+			f := &l.Fields[i]
+			if f.Kind == lint.KindFlag && strings.ToUpper(f.Label) != f.Label {
+				ds = append(ds, f.At("Flag label must be upper-case."))
+			}
+		}
+		return
+	})
+	l, _ := lint.Parse(`'ACK= 'syn= Id:FHH`)
+	for _, d := range upper.Check(l) {
+		fmt.Printf("%s %s [%d:%d]\n", upper.ID(), d.Msg, d.Pos, d.End)
+	}
+	// Output:
+	// HR001 Flag label must be upper-case. [10:11]
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strconv"
+)
+
+// srcFile is a go source parsed on demand, for field= markers.
+type srcFile struct {
+	fn   string
+	src  []byte
+	fset *token.FileSet
+	af   *ast.File
+	err  error
+}
+
+func (sf *srcFile) parse() error {
+	if sf.fset == nil {
+		sf.fset = token.NewFileSet()
+		sf.af, sf.err = parser.ParseFile(sf.fset, sf.fn, sf.src, parser.SkipObjectResolution)
+	}
+	return sf.err
+}
+
+// fieldAt finds the string literal that a field= marker ending at offset
+// off points at. It is the value keyed with field in the composite literal
+// that follows the marker, or the argument of the following call to
+// a function declared in this file with a parameter named field. Of
+// literals and calls nested on that line the innermost one having field
+// is taken. Field may also be given as a 1-based position of the element
+// or argument of the outermost one. Then skip strings of the value are
+// passed over. It returns the source offset of the literal.
+func (sf *srcFile) fieldAt(off int, field string, skip int) (int, error) {
+	if err := sf.parse(); err != nil {
+		return 0, fmt.Errorf("Can not resolve field=%s: %s", field, err)
+	}
+	tf := sf.fset.File(sf.af.Pos())
+	line := sf.codeLine(off)
+	var nodes []ast.Node // literals and calls on the line after marker, outer first
+	ast.Inspect(sf.af, func(n ast.Node) bool {
+		if n == nil || tf.Offset(n.End()) <= off || tf.Line(n.Pos()) > line {
+			return false
+		}
+		switch n.(type) {
+		case *ast.CompositeLit, *ast.CallExpr:
+			if tf.Offset(n.Pos()) >= off && tf.Line(n.Pos()) == line {
+				nodes = append(nodes, n)
+			}
+		}
+		return true
+	})
+	if len(nodes) == 0 {
+		return 0, fmt.Errorf("No literal nor call follows the field=%s marker.", field)
+	}
+	vs, names := sf.elems(nodes[0])
+	i, err := strconv.Atoi(field)
+	if err != nil {
+	inner:
+		for _, n := range nodes[1:] {
+			v, ks := sf.elems(n)
+			for _, k := range ks {
+				if k == field {
+					vs, names = v, ks
+					continue inner
+				}
+			}
+		}
+	}
+	switch {
+	case err == nil && (i < 1 || i > len(vs)):
+		return 0, fmt.Errorf("There is no field=%d of %d.", i, len(vs))
+	case err == nil:
+		i--
+	default:
+		for i = 0; i < len(names) && names[i] != field; i++ {
+		}
+		if i >= len(vs) {
+			return 0, fmt.Errorf("No %s: key nor parameter in the literal or call below.", field)
+		}
+	}
+	v := vs[i]
+	if kv, ok := v.(*ast.KeyValueExpr); ok {
+		v = kv.Value
+	}
+	r := -1
+	ast.Inspect(v, func(n ast.Node) bool {
+		if b, ok := n.(*ast.BasicLit); ok && b.Kind == token.STRING && r < 0 {
+			if skip--; skip < 0 {
+				r = tf.Offset(b.Pos())
+			}
+		}
+		return r < 0
+	})
+	if r < 0 {
+		return 0, fmt.Errorf("No string to take at field=%s.", field)
+	}
+	return r, nil
+}
+
+// elems returns values of a composite literal or arguments of a call,
+// with their keys or parameter names where known.
+func (sf *srcFile) elems(node ast.Node) (vs []ast.Expr, names []string) {
+	switch n := node.(type) {
+	case *ast.CompositeLit:
+		vs = n.Elts
+		for _, v := range n.Elts {
+			var k string
+			if kv, ok := v.(*ast.KeyValueExpr); ok {
+				if id, ok := kv.Key.(*ast.Ident); ok {
+					k = id.Name
+				}
+			}
+			names = append(names, k)
+		}
+	case *ast.CallExpr:
+		vs = n.Args
+		names = sf.params(n.Fun)
+	}
+	return
+}
+
+// params returns parameter names of a function (or method) declared in
+// the file, if fun calls one.
+func (sf *srcFile) params(fun ast.Expr) (r []string) {
+	var name string
+	switch f := fun.(type) {
+	case *ast.Ident:
+		name = f.Name
+	case *ast.SelectorExpr:
+		name = f.Sel.Name
+	}
+	for _, d := range sf.af.Decls {
+		fd, ok := d.(*ast.FuncDecl)
+		if !ok || fd.Name.Name != name {
+			continue
+		}
+		for _, p := range fd.Type.Params.List {
+			for _, n := range p.Names {
+				r = append(r, n.Name)
+			}
+		}
+		return
+	}
+	return
+}
+
+// codeLine returns line of the first token past the offset.
+func (sf *srcFile) codeLine(off int) int {
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	tf := fset.AddFile(sf.fn, -1, len(sf.src))
+	s.Init(tf, sf.src, nil, 0)
+	for {
+		p, t, _ := s.Scan()
+		if t == token.EOF {
+			return -1
+		}
+		if tf.Offset(p) >= off && t != token.SEMICOLON {
+			return tf.Line(p)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	ts "text/scanner"
+	"unicode"
+)
+
+// candidate is an unmarked string literal that looks like a picstring.
+type candidate struct {
+	pos   ts.Position
+	pic   string
+	score int
+	why   []string
+	skip  int    // strings before it on its line
+	tag   string // suggested
+}
+
+var (
+	reVarb = regexp.MustCompile(`(D\.*|!)[0-9][0-9]@`)
+	reHex  = regexp.MustCompile(`(^|[^A-Za-z])[BEF]?HH*($|[^A-Za-z])`)
+	reFlag = regexp.MustCompile(`'[A-Z][A-Za-z0-9]*[<=>?]`)
+	reVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*[vdsqxXfgeTtpcbo]`)
+)
+
+// scorePic tells how likely s is a picstring, 0..100, and why.
+func scorePic(s string) (score int, why []string) {
+	add := func(n int, w string) {
+		score += n
+		why = append(why, w)
+	}
+	if strings.ContainsRune(s, '¨') {
+		return 0, nil // our own map
+	}
+	if reVarb.MatchString(s) {
+		add(50, `dd@`)
+	}
+	if strings.Contains(s, `IPv4.Address32@`) {
+		add(50, `IPv4`)
+	}
+	if reHex.MatchString(s) {
+		add(20, `hex`)
+	}
+	if n := len(reFlag.FindAllString(s, 2)); n > 0 {
+		add(15*n, `'FLAG=`)
+	}
+	l, ds := Check(s)
+	switch {
+	case len(l.Fields) == 0:
+		return 0, nil
+	case len(ds) == 0 && len(l.Fields) > 1:
+		add(20, `clean`)
+	case len(ds) > 0:
+		add(-20, `errors`)
+	}
+	if reVerb.MatchString(s) {
+		add(-40, `fmt verbs`)
+	}
+	if score > 100 {
+		score = 100
+	}
+	return
+}
+
+// findFile returns unmarked candidates of file fn scoring at least least.
+func findFile(fn string, least int) (cs []candidate, err error) {
+	ps, _, err := scanFile(fn)
+	if err != nil {
+		return
+	}
+	taken := map[int]bool{}
+	for _, p := range ps {
+		if p.bad == nil {
+			taken[p.pos.Offset] = true
+		}
+	}
+	src, _ := os.ReadFile(fn)
+	var f ts.Scanner
+	f.Init(bytes.NewReader(src))
+	f.Filename = fn
+	f.Mode = ts.GoTokens
+	line, n := 0, 0 // strings met on the line
+	var ident, lhs, first string
+	var last rune
+	decl := false // lhs is the name of a var or const declared
+	for x := f.Scan(); x != ts.EOF; last, x = x, f.Scan() {
+		if f.Position.Line != line {
+			line, n, ident, lhs, first, last, decl = f.Position.Line, 0, ``, ``, ``, 0, false
+		}
+		switch x {
+		case ts.Ident:
+			t := f.TokenText()
+			if ident == `var` || ident == `const` {
+				lhs, decl = t, true
+			}
+			ident = t
+		case '=', ':': // x = , x := and Key: name what receives the string
+			if last == ts.Ident && !decl {
+				lhs = ident
+			}
+		case ts.String, ts.RawString:
+			n++
+			s := f.TokenText()
+			s = s[1 : len(s)-1]
+			if n == 1 {
+				first = s
+			}
+			// scanFile positions are taken past the token
+			if taken[f.Position.Offset+len(f.TokenText())] {
+				continue
+			}
+			sc, why := scorePic(s)
+			if sc < least {
+				continue
+			}
+			tag := lhs
+			if len(tag) == 0 && n > 1 {
+				tag = strings.Map(func(r rune) rune {
+					if r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+						return r
+					}
+					return -1
+				}, first)
+			}
+			switch tag {
+			case ``:
+				tag = `unnamed`
+			case `begin`, `end`: // would mark a region
+				tag += `_`
+			}
+			cs = append(cs, candidate{f.Position, s, sc, why, n - 1, tag})
+		}
+	}
+	return
+}
+
+// marker returns the marker line suggested for the candidate.
+func (c *candidate) marker() string {
+	if c.skip > 0 {
+		return fmt.Sprintf("//bitpeek:%s skip=%d", c.tag, c.skip)
+	}
+	return `//bitpeek:` + c.tag
+}
+
+// insertMarkers puts suggested markers above lines of candidates,
+// indented as the line is. Only the first candidate of a line is marked.
+func insertMarkers(fn string, cs []candidate) error {
+	src, err := os.ReadFile(fn)
+	if err != nil {
+		return err
+	}
+	lines := strings.SplitAfter(string(src), "\n")
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].pos.Line > cs[j].pos.Line })
+	done := map[int]bool{}
+	for _, c := range cs {
+		i := c.pos.Line - 1
+		if done[i] || i >= len(lines) {
+			continue
+		}
+		done[i] = true
+		ind := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+		lines = append(lines[:i], append([]string{ind + c.marker() + "\n"}, lines[i:]...)...)
+	}
+	return os.WriteFile(fn, []byte(strings.Join(lines, ``)), 0644)
+}
+
+// runFind is the 'bplint find' command.
+func runFind(args []string) {
+	least := 50
+	write := false
+	fwd := false
+	found := 0
+	for i, v := range args {
+		switch {
+		case fwd:
+			fwd = false
+			continue
+		case v == `-w`:
+			write = true
+		case v == `-min` && i < len(args)-1:
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 || n > 100 {
+				prErr("Error: bad -min "+args[i+1]+", need a score 0..100", false)
+				os.Exit(1)
+			}
+			least = n
+			fwd = true
+		default:
+			cs, err := findFile(v, least)
+			if err != nil {
+				prErr(fmt.Sprintf("Can not %s", err), quiet)
+				errcnt++
+				continue
+			}
+			files++
+			found += len(cs)
+			for _, c := range cs {
+				fmt.Printf("%s:%d:%d: score %d (%s)\n\t%s\n\tinsert %s above line %d\n",
+					c.pos.Filename, c.pos.Line, c.pos.Column, c.score,
+					strings.Join(c.why, ", "), c.pic, c.marker(), c.pos.Line)
+			}
+			if write && len(cs) > 0 {
+				if err := insertMarkers(v, cs); err != nil {
+					prErr(fmt.Sprintf("Can not %s", err), quiet)
+					errcnt++
+				}
+			}
+		}
+	}
+	if files == 0 {
+		prErr(`Error: no files given and/or no files checked!`, quiet)
+		usage()
+	}
+	fmt.Printf("--- %d picstring candidates in %d files -\n", found, files)
+	if errcnt > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var scoreTests = []struct {
+	s    string
+	pick bool // scores 50 or more
+}{
+	{`Type:'F 'EXT=.ACK= Id:0xFHH from IPv4.Address32@:D.16@`, true},
+	{`IPv4.Address32@`, true},
+	{`Id:FHH 'ACK=`, true},
+	{`D.16@`, true},
+	{`Hello, World!`, false},
+	{`HTTP/1.1 %d %s`, false},
+	{`Usage: %s [options] file [file...]`, false},
+	{`cmds:¨¨Type:'F¨ 'EXT=¨`, false},
+	{`github.com/ohir/bplint/lint`, false},
+}
+
+func TestScorePic(t *testing.T) {
+	for _, v := range scoreTests {
+		sc, why := scorePic(v.s)
+		if (sc >= 50) != v.pick {
+			t.Errorf("%q scored %d (%s)", v.s, sc, strings.Join(why, `, `))
+		}
+	}
+}
+
+func TestFindInsert(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), `x.go`)
+	src := "package x\n\nvar a = []struct{ d, p string }{\n\t{`Status`, `Id:FHH 'ACK=`},\n\t//bitpeek:done:1\n\t{`Done`, `Id:FHH 'ACK=`},\n}\n"
+	os.WriteFile(fn, []byte(src), 0644)
+	cs, err := findFile(fn, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 1 || cs[0].marker() != `//bitpeek:Status skip=1` {
+		t.Fatalf("bad candidates: %v", cs)
+	}
+	if err := insertMarkers(fn, cs); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(fn)
+	if !strings.Contains(string(b), "{\n\t//bitpeek:Status skip=1\n\t{`Status`") {
+		t.Errorf("marker not inserted:\n%s", b)
+	}
+	if cs, _ := findFile(fn, 50); len(cs) != 0 {
+		t.Errorf("marked candidate found again")
+	}
+}
+
+// Skips past 7 and tags begin or end must make markers read back as written.
+func TestFindMarker(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), `x.go`)
+	src := "package x\n\nfunc f() {\n\tg(`x`, `2`, `3`, `4`, `5`, `6`, `7`, `8`, `Id:FHH 'ACK=`)\n" +
+		"\tg(`end`, `Id:FHH 'ACK=`)\n}\n"
+	os.WriteFile(fn, []byte(src), 0644)
+	cs, err := findFile(fn, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cs) != 2 || cs[0].marker() != `//bitpeek:x skip=8` || cs[1].marker() != `//bitpeek:end_ skip=1` {
+		t.Fatalf("bad candidates: %v", cs)
+	}
+	if err := insertMarkers(fn, cs); err != nil {
+		t.Fatal(err)
+	}
+	ps, _, err := scanFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != 2 || ps[0].bad != nil || ps[1].bad != nil {
+		t.Fatalf("inserted markers not read back: %v", ps)
+	}
+	for i, p := range ps {
+		if p.pic != cs[i].pic {
+			t.Errorf("marker %d reads %q", i, p.pic)
+		}
+	}
+}
+
+func TestFindTag(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), `x.go`)
+	src := "package x\n\nconst Hdr = `Id:FHH 'ACK=`\n\nvar Opt string = `Id:FHH 'ACK=`\n\n" +
+		"func f() {\n\tfmt.Println(`Id:FHH 'ACK=`)\n\tp := fmt.Sprint(`Id:FHH 'ACK=`)\n" +
+		"\tr := T{Name: `n`, Pic: `Id:FHH 'ACK=`}\n\tm[k] = `Id:FHH 'ACK=`\n}\n"
+	os.WriteFile(fn, []byte(src), 0644)
+	cs, err := findFile(fn, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []string
+	for _, c := range cs {
+		tags = append(tags, c.tag)
+	}
+	if s := strings.Join(tags, ` `); s != `Hdr Opt unnamed p Pic unnamed` {
+		t.Errorf("bad tags: %s", s)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+	ts "text/scanner"
+)
+
+// genPic is a marked picstring given to a generator.
+type genPic struct {
+	tag   string
+	pos   ts.Position
+	l     *Layout
+	names []string // of fields, see Layout.Names
+	typ   string   // type name, -type or made of the tag
+	pkg   string   // package name, -pkg or of the source file
+}
+
+// generators by the language name of 'bplint gen LANG'.
+var generators = map[string]func(g *genPic) ([]byte, error){
+	`c`:         genC,
+	`go`:        genGo,
+	`ksy`:       genKsy,
+	`sv`:        genSV,
+	`wavedrom`:  genWaveDrom,
+	`wireshark`: genWireshark,
+}
+
+// ident makes an exported identifier of s, dropping non word chars
+// and capitalizing what follows them, eg. tbl/first gives TblFirst.
+func ident(s string) string {
+	var b strings.Builder
+	up := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case !isWordChar(c) || c == '_':
+			up = true
+		case b.Len() == 0 && c >= '0' && c <= '9':
+			b.WriteByte('X')
+			b.WriteByte(c)
+			up = false
+		case up && c >= 'a' && c <= 'z':
+			b.WriteByte(c &^ 0x20)
+			up = false
+		default:
+			b.WriteByte(c)
+			up = false
+		}
+	}
+	if b.Len() == 0 {
+		return `Pic`
+	}
+	return b.String()
+}
+
+// pickGen finds the picstring marked with tag in files. It must lint
+// clean, generators take no guesses.
+func pickGen(tag string, fns []string) (*genPic, error) {
+	for _, fn := range fns {
+		ps, _, err := scanFile(fn)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range ps {
+			if p.bad != nil || p.tag != tag {
+				continue
+			}
+			l, ds := check(p.pic, p.mk.width, nil, num)
+			if len(ds) > 0 {
+				return nil, fmt.Errorf("%s line %d: picstring %q does not lint clean: %s [%s]",
+					p.pos.Filename, p.pos.Line, tag, ds[0].Msg, ds[0].Rule)
+			}
+			g := &genPic{tag: tag, pos: p.pos, l: l, names: l.Names(), typ: ident(tag)}
+			if af, err := parser.ParseFile(token.NewFileSet(), fn, nil, parser.PackageClauseOnly); err == nil {
+				g.pkg = af.Name.Name
+			}
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("no picstring marked %q in given files", tag)
+}
+
+// runGen is the 'bplint gen LANG' command.
+func runGen(args []string) {
+	var tag, typ, pkg, out string
+	var fns []string
+	lang := ``
+	if len(args) > 0 {
+		lang, args = args[0], args[1:]
+	}
+	gen := generators[lang]
+	if gen == nil {
+		var ls []string
+		for k := range generators {
+			ls = append(ls, k)
+		}
+		sort.Strings(ls)
+		prErr(fmt.Sprintf("Error: gen needs a language, one of: %s", strings.Join(ls, `, `)), false)
+		os.Exit(1)
+	}
+	fwd := false
+	for i, v := range args {
+		switch {
+		case fwd:
+			fwd = false
+			continue
+		case (v == `-bits` || v == `-endian`) && i < len(args)-1:
+			setNum(v, args[i+1])
+			fwd = true
+		case v == `-t` && i < len(args)-1:
+			tag = args[i+1]
+			fwd = true
+		case v == `-type` && i < len(args)-1:
+			typ = args[i+1]
+			fwd = true
+		case v == `-pkg` && i < len(args)-1:
+			pkg = args[i+1]
+			fwd = true
+		case v == `-o` && i < len(args)-1:
+			out = args[i+1]
+			fwd = true
+		default:
+			fns = append(fns, v)
+		}
+	}
+	if len(tag) == 0 || len(fns) == 0 {
+		prErr(`Error: gen needs -t TAG and files to look in!`, false)
+		usage()
+	}
+	g, err := pickGen(tag, fns)
+	if err == nil {
+		if len(typ) > 0 {
+			g.typ = typ
+		}
+		if len(pkg) > 0 {
+			g.pkg = pkg
+		}
+		var b []byte
+		if b, err = gen(g); err == nil {
+			if len(out) == 0 {
+				_, err = os.Stdout.Write(b)
+			} else {
+				err = os.WriteFile(out, b, 0644)
+			}
+		}
+	}
+	if err != nil {
+		prErr(fmt.Sprintf("Can not generate: %s", err), false)
+		os.Exit(1)
+	}
+}
+
+// idents returns names of fields made identifiers by mk, kept unique
+// after it: Id and ID that both make id come out as id and id_2. Names
+// given in also are taken already, eg. pad.
+func (g *genPic) idents(mk func(string) string, also ...string) []string {
+	taken := map[string]bool{}
+	for _, v := range also {
+		taken[v] = true
+	}
+	r := make([]string, len(g.names))
+	for i, v := range g.names {
+		n := mk(v)
+		for k := 2; taken[n]; k++ {
+			n = fmt.Sprintf("%s_%d", mk(v), k)
+		}
+		taken[n] = true
+		r[i] = n
+	}
+	return r
+}
+
+// reserved returns names generators give to skips and unused bits.
+func (g *genPic) reserved() []string {
+	r := []string{`pad`}
+	for i := range g.l.Fields {
+		if f := &g.l.Fields[i]; f.Kind == KindSkip {
+			r = append(r, fmt.Sprintf("reserved%d", f.Lo))
+		}
+	}
+	return r
+}
+
+// bits tells which bits field takes, for comments.
+func (g *genPic) bits(f *Field) string {
+	if f.Width == 1 {
+		return fmt.Sprintf("bit %d", g.l.bitNo(f.Lo, false))
+	}
+	return `bits ` + g.l.bitSpan(f.Hi(), f.Lo)
+}
+
+// mapComment returns lint map of the picstring as comment lines
+// starting with pfx.
+func (g *genPic) mapComment(pfx string) string {
+	var b bytes.Buffer
+	m := lintMap(g.l, nil, utf8R)
+	for _, v := range m[1:] {
+		fmt.Fprintf(&b, "%s%s\n", pfx, strings.TrimRight(v, ` `))
+	}
+	return b.String()
+}
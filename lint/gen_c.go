@@ -0,0 +1,68 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// upperSnake makes C macro name part of an identifier: TblFirst gives
+// TBL_FIRST, Mode_2 gives MODE_2.
+func upperSnake(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if i > 0 && c >= 'A' && c <= 'Z' {
+			if p := s[i-1]; p >= 'a' && p <= 'z' || p >= '0' && p <= '9' {
+				b.WriteByte('_')
+			}
+		}
+		if c >= 'a' && c <= 'z' {
+			c &^= 0x20
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// genC makes a C header with SHIFT, MASK, GET and SET macros for every
+// field. Map of the picstring goes into the header comment.
+func genC(g *genPic) ([]byte, error) {
+	var b bytes.Buffer
+	t := upperSnake(ident(g.typ))
+	u, sfx := goUint(g.l.Width)+`_t`, `u`
+	if g.l.Width > 32 {
+		sfx = `ULL`
+	}
+	guard := t + `_H`
+	fmt.Fprintf(&b, "/* Code generated by bplint gen c -t %s; DO NOT EDIT.\n *\n", g.tag)
+	fmt.Fprintf(&b, " * %s fields, %d bits, of the picstring:\n *\n", t, g.l.Width)
+	fmt.Fprintf(&b, " *   %s\n *\n", cComment(g.l.Pic))
+	// inserts marked as the ascii map does, | would read as a bit separator
+	b.WriteString(cComment(strings.Replace(g.mapComment(` *   `), `¨`, string(asciiR.fill()), -1)))
+	fmt.Fprintf(&b, " */\n#ifndef %s\n#define %s\n\n#include <stdint.h>\n", guard, guard)
+	ns := g.idents(func(s string) string { return upperSnake(ident(s)) })
+	for i := range g.l.Fields {
+		f := &g.l.Fields[i]
+		if f.Kind == KindSkip {
+			continue
+		}
+		n := t + `_` + ns[i]
+		fmt.Fprintf(&b, "\n/* %s: %s */\n", g.names[i], g.bits(f))
+		fmt.Fprintf(&b, "#define %s_SHIFT %d\n", n, f.Lo)
+		fmt.Fprintf(&b, "#define %s_MASK  (%#x%s << %s_SHIFT)\n", n, uint64(1)<<uint(f.Width)-1, sfx, n)
+		fmt.Fprintf(&b, "#define %s_GET(x) (((x) & %s_MASK) >> %s_SHIFT)\n", n, n, n)
+		fmt.Fprintf(&b, "#define %s_SET(x, v) (((x) & ~%s_MASK) | ((%s)(v) << %s_SHIFT & %s_MASK))\n", n, n, u, n, n)
+	}
+	fmt.Fprintf(&b, "\n#endif /* %s */\n", guard)
+	return b.Bytes(), nil
+}
+
+// cComment keeps s from closing the comment it is put in.
+func cComment(s string) string {
+	return strings.Replace(s, `*/`, `*\/`, -1)
+}
@@ -0,0 +1,74 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+)
+
+// goUint returns the smallest Go unsigned type that holds width bits.
+func goUint(width int) string {
+	for _, n := range []int{8, 16, 32} {
+		if width <= n {
+			return fmt.Sprintf("uint%d", n)
+		}
+	}
+	return `uint64`
+}
+
+// genGo makes a Go file with a named type for the picstring, shift and
+// mask constants and accessors for its fields, and a String method that
+// shows the value with bitpeek.
+func genGo(g *genPic) ([]byte, error) {
+	var b bytes.Buffer
+	t, u := g.typ, goUint(g.l.Width)
+	pkg := g.pkg
+	if len(pkg) == 0 {
+		pkg = `main`
+	}
+	fmt.Fprintf(&b, "// Code generated by bplint gen go -t %s; DO NOT EDIT.\n\n", g.tag)
+	fmt.Fprintf(&b, "package %s\n\nimport \"github.com/ohir/bitpeek\"\n\n", pkg)
+	fmt.Fprintf(&b, "// %s holds fields of the %q picstring:\n//\n%s", t, g.tag, g.mapComment("//\t"))
+	fmt.Fprintf(&b, "type %s %s\n\n", t, u)
+	fmt.Fprintf(&b, "// %sPic is the picstring that %s.String shows.\nconst %sPic = %q\n\n", t, t, t, g.l.Pic)
+	ns := g.idents(ident)
+	b.WriteString("const (\n")
+	for i := range g.l.Fields {
+		f := &g.l.Fields[i]
+		if f.Kind == KindSkip {
+			continue
+		}
+		n := t + ns[i]
+		fmt.Fprintf(&b, "%sShift = %d\n%sMask = %#x << %sShift\n", n, f.Lo, n, uint64(1)<<uint(f.Width)-1, n)
+	}
+	b.WriteString(")\n")
+	for i := range g.l.Fields {
+		f := &g.l.Fields[i]
+		if f.Kind == KindSkip {
+			continue
+		}
+		m := ns[i]
+		if m == `String` {
+			m += `_`
+		}
+		n := t + ns[i]
+		if f.Kind == KindFlag {
+			fmt.Fprintf(&b, "\n// %s tells whether %s is set.\n", m, g.bits(f))
+			fmt.Fprintf(&b, "func (r %s) %s() bool { return r&%sMask != 0 }\n", t, m, n)
+			fmt.Fprintf(&b, "\n// Set%s sets or clears %s.\n", m, g.bits(f))
+			fmt.Fprintf(&b, "func (r *%s) Set%s(v bool) {\nif v {\n*r |= %sMask\n} else {\n*r &^= %sMask\n}\n}\n", t, m, n, n)
+			continue
+		}
+		fmt.Fprintf(&b, "\n// %s returns %s.\n", m, g.bits(f))
+		fmt.Fprintf(&b, "func (r %s) %s() %s { return %s(r&%sMask) >> %sShift }\n", t, m, u, u, n, n)
+		fmt.Fprintf(&b, "\n// Set%s puts v into %s.\n", m, g.bits(f))
+		fmt.Fprintf(&b, "func (r *%s) Set%s(v %s) { *r = *r&^%sMask | %s(v<<%sShift)&%sMask }\n", t, m, u, n, t, n, n)
+	}
+	fmt.Fprintf(&b, "\n// String shows r as %sPic tells.\n", t)
+	fmt.Fprintf(&b, "func (r %s) String() string { return string(bitpeek.Pic(%sPic, uint64(r))) }\n", t, t)
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,54 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// snake makes lower snake case identifier of a name, for formats that
+// want one: Kaitai ids, Lua variables.
+func snake(s string) string {
+	return strings.ToLower(upperSnake(ident(s)))
+}
+
+// ksyType returns Kaitai type of field: b<N> bits, or an unsigned
+// integer u1..u8 for decimals and IPv4 addresses that sit on a byte.
+func ksyType(f *Field, width int) string {
+	at := width - 1 - f.Hi() // from the start of the word, big-endian
+	switch {
+	case f.Kind != KindDec && f.Kind != KindIPv4, at%8 != 0:
+	case f.Width == 8, f.Width == 16, f.Width == 32, f.Width == 64:
+		return fmt.Sprintf("u%d", f.Width/8)
+	}
+	return fmt.Sprintf("b%d", f.Width)
+}
+
+// genKsy makes a Kaitai Struct spec with a seq of the fields, most
+// significant first, in big-endian bit order. Bits above the last field
+// up to the declared width are read as pad.
+func genKsy(g *genPic) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Code generated by bplint gen ksy -t %s; DO NOT EDIT.\n#\n", g.tag)
+	fmt.Fprintf(&b, "#   %s\n#\n%s", g.l.Pic, g.mapComment(`#   `))
+	fmt.Fprintf(&b, "meta:\n  id: %s\n  endian: be\n  bit-endian: be\nseq:\n", snake(g.typ))
+	if pad := g.l.Width - g.l.Bits; pad > 0 {
+		fmt.Fprintf(&b, "  - id: pad\n    type: b%d\n    doc: \"bits %d..%d unused\"\n", pad, g.l.Width-1, g.l.Bits)
+	}
+	ns := g.idents(snake, g.reserved()...)
+	for i := range g.l.Fields {
+		f := &g.l.Fields[i]
+		n := ns[i]
+		if f.Kind == KindSkip {
+			n = fmt.Sprintf("reserved%d", f.Lo)
+		}
+		fmt.Fprintf(&b, "  - id: %s\n    type: %s\n    doc: %s\n", n, ksyType(f, g.l.Width),
+			strconv.Quote(fmt.Sprintf("%s: %s", g.bits(f), strings.TrimSpace(f.Pic()))))
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// svKeywords that may come out of labels, they get a trailing _.
+var svKeywords = map[string]bool{
+	`bit`: true, `byte`: true, `int`: true, `logic`: true, `reg`: true,
+	`wire`: true, `type`: true, `string`: true, `struct`: true, `enum`: true,
+	`input`: true, `output`: true, `inout`: true, `module`: true, `begin`: true,
+	`end`: true, `case`: true, `if`: true, `else`: true, `for`: true,
+	`do`: true, `while`: true, `packed`: true, `signed`: true, `unsigned`: true,
+	`time`: true, `event`: true, `real`: true, `const`: true, `class`: true,
+	`function`: true, `task`: true, `return`: true, `default`: true,
+	`null`: true, `this`: true, `super`: true, `local`: true, `static`: true,
+	`assign`: true, `always`: true, `initial`: true, `package`: true,
+}
+
+// svName makes lower snake case SV identifier of a field name.
+func svName(s string) string {
+	n := strings.ToLower(upperSnake(ident(s)))
+	if svKeywords[n] {
+		n += `_`
+	}
+	return n
+}
+
+// genSV makes a SystemVerilog packed struct for the picstring, most
+// significant field first. Skips become reserved fields and bits above
+// the last field, up to the declared width, a pad.
+func genSV(g *genPic) ([]byte, error) {
+	type member struct{ typ, name, note string }
+	var ms []member
+	logic := func(w int) string {
+		if w == 1 {
+			return `logic`
+		}
+		return fmt.Sprintf("logic [%d:0]", w-1)
+	}
+	if pad := g.l.Width - g.l.Bits; pad > 0 {
+		ms = append(ms, member{logic(pad), `pad`,
+			fmt.Sprintf("bits %d..%d unused", g.l.Width-1, g.l.Bits)})
+	}
+	ns := g.idents(svName, g.reserved()...)
+	for i := range g.l.Fields {
+		f := &g.l.Fields[i]
+		n := ns[i]
+		if f.Kind == KindSkip {
+			n = fmt.Sprintf("reserved%d", f.Lo)
+		}
+		ms = append(ms, member{logic(f.Width), n, fmt.Sprintf("%s: %s", g.bits(f), strings.TrimSpace(f.Pic()))})
+	}
+	tw, nw := 0, 0
+	for _, m := range ms {
+		tw, nw = max(tw, len(m.typ)), max(nw, len(m.name)+1)
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by bplint gen sv -t %s; DO NOT EDIT.\n//\n", g.tag)
+	fmt.Fprintf(&b, "// %d bits of the picstring:\n//\n//   %s\n//\n%s", g.l.Width, g.l.Pic, g.mapComment(`//   `))
+	b.WriteString("typedef struct packed {\n")
+	for _, m := range ms {
+		fmt.Fprintf(&b, "  %-*s %-*s // %s\n", tw, m.typ, nw, m.name+`;`, m.note)
+	}
+	fmt.Fprintf(&b, "} %s_t;\n", strings.ToLower(upperSnake(ident(g.typ))))
+	return b.Bytes(), nil
+}
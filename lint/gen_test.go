@@ -0,0 +1,199 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+)
+
+var nameTests = []struct {
+	pic   string
+	names string
+}{
+	{`Type:'F 'EXT=.ACK= Id:0xFHH from IPv4.Address32@:D.16@`, `Type EXT ACK Id Addr16 Dec0`},
+	{`Tśćę:'F 'EXT=.ACK= Ąę:0xFHH IPv4.Address32@`, `Num45 EXT ACK Hex32 Addr0`},
+	{`Mode:EH Mode:F !12@ 'Some Flag''ER?`, `Mode Mode_2 Skip1 ER`},
+	{`len:FH kind#EH 0:HH`, `len kind Hex0`},
+	{`'Version':H 'Type of Service':HH 'Ąę':H 'R0=`, `Version TypeOfService Hex1 R0`},
+}
+
+func TestNames(t *testing.T) {
+	for _, v := range nameTests {
+		l, _ := Parse(v.pic)
+		if r := strings.Join(l.Names(), ` `); r != v.names {
+			t.Errorf("%q: got names %q, expected %q", v.pic, r, v.names)
+		}
+	}
+}
+
+func TestIdent(t *testing.T) {
+	for k, v := range map[string]string{`tbl/first`: `TblFirst`, `Hdr`: `Hdr`,
+		`rx_frame-v2`: `RxFrameV2`, `2nd`: `X2nd`, `//`: `Pic`} {
+		if r := ident(k); r != v {
+			t.Errorf("ident(%q) = %q, expected %q", k, r, v)
+		}
+	}
+}
+
+func TestGenGo(t *testing.T) {
+	g, err := pickGen(`Example`, []string{`lint_test.go`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := genGo(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{
+		"package lint\n",
+		"type Example uint64\n",
+		"ExampleIdMask      = 0x7ff << ExampleIdShift\n",
+		"func (r Example) EXT() bool { return r&ExampleEXTMask != 0 }\n",
+		"func (r Example) Type() uint64 { return uint64(r&ExampleTypeMask) >> ExampleTypeShift }\n",
+		"return string(bitpeek.Pic(ExamplePic, uint64(r)))",
+	} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("generated code lacks %q:\n%s", v, b)
+		}
+	}
+	if _, err := pickGen(`Ovl`, []string{`lint_test.go`}); err == nil {
+		t.Errorf("gen took a picstring that does not lint clean")
+	}
+}
+
+func TestGenC(t *testing.T) {
+	g, err := pickGen(`Example`, []string{`lint_test.go`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.typ = `RxHdr`
+	b, _ := genC(g)
+	for _, v := range []string{
+		" *   cmds:``Type:'F` 'EXT=`.ACK=` Id:0xFHH` from IPv4.Address32@```:D.16@`\n",
+		"#ifndef RX_HDR_H\n",
+		"#define RX_HDR_ID_MASK  (0x7ffULL << RX_HDR_ID_SHIFT)\n",
+		"#define RX_HDR_EXT_GET(x) (((x) & RX_HDR_EXT_MASK) >> RX_HDR_EXT_SHIFT)\n",
+		"#define RX_HDR_DEC0_SET(x, v) (((x) & ~RX_HDR_DEC0_MASK) | ((uint64_t)(v) << RX_HDR_DEC0_SHIFT & RX_HDR_DEC0_MASK))\n",
+	} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("generated header lacks %q:\n%s", v, b)
+		}
+	}
+	if r := upperSnake(`TblFirst2nd`); r != `TBL_FIRST2ND` {
+		t.Errorf("upperSnake gave %s", r)
+	}
+}
+
+func TestGenSV(t *testing.T) {
+	g, err := pickGen(`status`, []string{`testdata/gen.go`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := genSV(g)
+	want := "typedef struct packed {\n" +
+		"  logic [7:0]  pad;       // bits 39..32 unused\n" +
+		"  logic [2:0]  type_;     // bits 31..29: Type:'F\n" +
+		"  logic        ext;       // bit 28: 'EXT=\n" +
+		"  logic        ack;       // bit 27: .ACK=\n" +
+		"  logic [10:0] id;        // bits 26..16: Id:0xFHH\n" +
+		"  logic [7:0]  reserved8; // bits 15..8: !08@\n" +
+		"  logic [7:0]  len;       // bits 7..0: Len:HH\n" +
+		"} status_t;\n"
+	if !strings.HasSuffix(string(b), want) {
+		t.Errorf("got:\n%s\nexpected it to end with:\n%s", b, want)
+	}
+}
+
+func TestGenKsy(t *testing.T) {
+	for _, v := range []struct{ tag, fn, want string }{
+		{`status`, `testdata/gen.go`, "  - id: type\n    type: b3\n    doc: \"bits 31..29: Type:'F\"\n"},
+		{`status`, `testdata/gen.go`, "  - id: reserved8\n    type: b8\n"},
+		{`Example`, `lint_test.go`, "  - id: addr16\n    type: u4\n"},
+		{`Example`, `lint_test.go`, "  - id: dec0\n    type: u2\n"},
+	} {
+		g, err := pickGen(v.tag, []string{v.fn})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b, _ := genKsy(g); !strings.Contains(string(b), v.want) {
+			t.Errorf("ksy of %s lacks %q:\n%s", v.tag, v.want, b)
+		}
+	}
+}
+
+// Names that differ in case only must stay apart after case folding.
+func TestGenIdents(t *testing.T) {
+	l, _ := Check(`Id:FH ID:HH id:B pad:B`)
+	g := &genPic{tag: `t`, l: l, names: l.Names(), typ: `T`}
+	for i, v := range []struct {
+		gen  func(g *genPic) ([]byte, error)
+		want []string
+	}{
+		{genSV, []string{` id;`, ` id_2;`, ` id_3;`, ` pad;`, ` pad_2;`}},
+		{genKsy, []string{"id: id\n", "id: id_2\n", "id: id_3\n", "id: pad\n", "id: pad_2\n"}},
+		{genC, []string{`T_ID_SHIFT`, `T_ID_2_SHIFT`, `T_ID_3_SHIFT`, `T_PAD_SHIFT`}},
+		{genGo, []string{`TIdShift`, `TIDShift`, `TId_2Shift`, `TPadShift`}},
+		{genWireshark, []string{`local t_id =`, `local t_id_2 =`, `local t_id_3 =`}},
+	} {
+		b, _ := v.gen(g)
+		for _, w := range v.want {
+			if !strings.Contains(string(b), w) {
+				t.Errorf("%d: output lacks %q:\n%s", i, w, b)
+			}
+		}
+	}
+}
+
+func TestGenWaveDrom(t *testing.T) {
+	g, err := pickGen(`status`, []string{`testdata/gen.go`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := genWaveDrom(g)
+	want := `{"reg": [
+  {"bits":8,"name":"Len","type":4},
+  {"bits":8,"type":1},
+  {"bits":11,"name":"Id","type":4},
+  {"bits":1,"name":"ACK","type":2},
+  {"bits":1,"name":"EXT","type":2},
+  {"bits":3,"name":"Type","type":4},
+  {"bits":8}
+], "config": {"bits": 40, "lanes": 2}}
+`
+	if string(b) != want {
+		t.Errorf("got:\n%s\nexpected:\n%s", b, want)
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite golden files of generators")
+
+// TestGenWireshark compares dissectors with golden files, run with
+// -update to rewrite them.
+func TestGenWireshark(t *testing.T) {
+	for _, v := range []struct{ tag, fn, golden string }{
+		{`Example`, `lint_test.go`, `testdata/golden/example.lua`},
+		{`status`, `testdata/gen.go`, `testdata/golden/status.lua`},
+	} {
+		g, err := pickGen(v.tag, []string{v.fn})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := genWireshark(g)
+		if *update {
+			os.WriteFile(v.golden, b, 0644)
+			continue
+		}
+		want, err := os.ReadFile(v.golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != string(want) {
+			t.Errorf("%s differs from %s, got:\n%s", v.tag, v.golden, b)
+		}
+	}
+}
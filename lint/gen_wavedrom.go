@@ -0,0 +1,60 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// WaveDrom reg field colors
+const (
+	wdFlag = 2
+	wdNum  = 4
+	wdSkip = 1
+)
+
+type wdField struct {
+	Bits int    `json:"bits"`
+	Name string `json:"name,omitempty"`
+	Type int    `json:"type,omitempty"`
+}
+
+// genWaveDrom makes WaveDrom bitfield (reg) JSON of the picstring.
+// WaveDrom lists fields from the least significant bit up. Skips and
+// bits above the last field get no name.
+func genWaveDrom(g *genPic) ([]byte, error) {
+	var fs []wdField
+	for i := len(g.l.Fields) - 1; i >= 0; i-- {
+		f := &g.l.Fields[i]
+		switch f.Kind {
+		case KindFlag:
+			fs = append(fs, wdField{f.Width, g.names[i], wdFlag})
+		case KindSkip:
+			fs = append(fs, wdField{f.Width, ``, wdSkip})
+		default:
+			fs = append(fs, wdField{f.Width, g.names[i], wdNum})
+		}
+	}
+	if pad := g.l.Width - g.l.Bits; pad > 0 {
+		fs = append(fs, wdField{Bits: pad})
+	}
+	var b bytes.Buffer
+	b.WriteString("{\"reg\": [\n")
+	for i, f := range fs {
+		j, err := json.Marshal(f)
+		if err != nil {
+			return nil, err
+		}
+		sep := ","
+		if i == len(fs)-1 {
+			sep = ``
+		}
+		fmt.Fprintf(&b, "  %s%s\n", j, sep)
+	}
+	fmt.Fprintf(&b, "], \"config\": {\"bits\": %d, \"lanes\": %d}}\n", g.l.Width, max(1, g.l.Width/16))
+	return b.Bytes(), nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// wsBase is the Wireshark base field values are shown in, by kind.
+var wsBase = [...]string{
+	KindBit: `base.DEC`, KindHex: `base.HEX`, KindOct: `base.OCT`,
+	KindNum: `base.DEC`, KindChar: `base.DEC`, KindDec: `base.DEC`,
+	KindIPv4: `base.HEX`,
+}
+
+// genWireshark makes a Lua dissector with a ProtoField for every field
+// but skips. The picstring word is read big-endian from the start of
+// the buffer, masks place fields in it. IPv4 addresses that sit on
+// a byte boundary are shown as ipv4 of their four bytes.
+func genWireshark(g *genPic) ([]byte, error) {
+	var b bytes.Buffer
+	p := snake(g.typ)
+	nb := (g.l.Width + 7) / 8 // word bytes
+	ut := `uint64`
+	for _, n := range []int{8, 16, 24, 32} {
+		if nb*8 <= n {
+			ut = fmt.Sprintf("uint%d", n)
+			break
+		}
+	}
+	mask := func(f *Field) string {
+		m := (uint64(1)<<uint(f.Width) - 1) << uint(f.Lo+nb*8-g.l.Width)
+		if nb > 4 {
+			return fmt.Sprintf("UInt64.fromhex(\"%0*x\")", nb*2, m)
+		}
+		return fmt.Sprintf("0x%0*x", nb*2, m)
+	}
+	fmt.Fprintf(&b, "-- Code generated by bplint gen wireshark -t %s; DO NOT EDIT.\n--\n", g.tag)
+	fmt.Fprintf(&b, "--   %s\n--\n%s\n", g.l.Pic, g.mapComment(`--   `))
+	fmt.Fprintf(&b, "local %s = Proto(%q, %q)\n\n", p, p, g.typ)
+	var vs, adds []string
+	ns := g.idents(snake)
+	for i := range g.l.Fields {
+		f := &g.l.Fields[i]
+		if f.Kind == KindSkip {
+			continue
+		}
+		v := p + `_` + ns[i]
+		abbr := p + `.` + ns[i]
+		name := f.Show(g.names[i])
+		at := g.l.Width - 1 - f.Hi() // from the start of the word
+		vs = append(vs, v)
+		switch {
+		case f.Kind == KindFlag:
+			fmt.Fprintf(&b, "local %s = ProtoField.bool(%q, %q, %d, nil, %s)\n", v, abbr, name, nb*8, mask(f))
+		case f.Kind == KindIPv4 && at%8 == 0:
+			fmt.Fprintf(&b, "local %s = ProtoField.ipv4(%q, %q)\n", v, abbr, name)
+			adds = append(adds, fmt.Sprintf("t:add(%s, buf(%d, 4))", v, at/8))
+			continue
+		default:
+			fmt.Fprintf(&b, "local %s = ProtoField.%s(%q, %q, %s, nil, %s)\n", v, ut, abbr, name, wsBase[f.Kind], mask(f))
+		}
+		adds = append(adds, fmt.Sprintf("t:add(%s, buf(0, %d))", v, nb))
+	}
+	fmt.Fprintf(&b, "\n%s.fields = { %s }\n\n", p, strings.Join(vs, `, `))
+	fmt.Fprintf(&b, "function %s.dissector(buf, pinfo, tree)\n", p)
+	fmt.Fprintf(&b, "  if buf:len() < %d then return 0 end\n", nb)
+	fmt.Fprintf(&b, "  pinfo.cols.protocol = %q\n", g.typ)
+	fmt.Fprintf(&b, "  local t = tree:add(%s, buf(0, %d))\n", p, nb)
+	for _, v := range adds {
+		fmt.Fprintf(&b, "  %s\n", v)
+	}
+	fmt.Fprintf(&b, "  return %d\nend\n", nb)
+	return b.Bytes(), nil
+}
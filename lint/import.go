@@ -0,0 +1,135 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// imported is a picstring made of a foreign description of a layout.
+type imported struct {
+	name  string // of the register or header
+	pic   string
+	width int // declared
+}
+
+// importOpts are options of 'bplint import'.
+type importOpts struct {
+	periph string // --peripheral
+	pkg    string // -pkg
+}
+
+// importers by the format name of 'bplint import FORMAT'.
+var importers = map[string]func(fn string, o *importOpts) (src string, ps []imported, err error){
+	`rfc`: importRFC,
+	`svd`: importSVD,
+}
+
+// picField returns picture of a field of w bits named name: a flag
+// for a single bit, hex number for more. Names are quoted as they may
+// hold command characters.
+func picField(name string, w int) string {
+	name = strings.NewReplacer(`'`, ``, `\`, ``, "`", ``, `=`, ``, `<`, ``, `>`, ``, `?`, ``).Replace(name)
+	if w == 1 {
+		return ` '` + name + `=`
+	}
+	return ` '` + name + `':` + hexPic(w)
+}
+
+// hexPic returns a hex number picture of w bits in the shape
+// ckRanges demands: single B, E or F then all Hs. Lone E and F stand
+// for 2 and 3 bit numbers.
+func hexPic(w int) string {
+	return [...]string{``, `B`, `E`, `F`}[w%4] + strings.Repeat(`H`, w/4)
+}
+
+// skipPic returns !dd@ skip of w bits.
+func skipPic(w int) string {
+	return fmt.Sprintf(" !%02d@", w)
+}
+
+// runImport is the 'bplint import FORMAT' command. It prints a go file
+// with the imported picstrings, each checked clean.
+func runImport(args []string) {
+	var o importOpts
+	var out string
+	var fns []string
+	format := ``
+	if len(args) > 0 {
+		format, args = args[0], args[1:]
+	}
+	imp := importers[format]
+	if imp == nil {
+		var fs []string
+		for k := range importers {
+			fs = append(fs, k)
+		}
+		sort.Strings(fs)
+		prErr(fmt.Sprintf("Error: import needs a format, one of: %s", strings.Join(fs, `, `)), false)
+		os.Exit(1)
+	}
+	fwd := false
+	for i, v := range args {
+		switch {
+		case fwd:
+			fwd = false
+			continue
+		case v == `--peripheral` && i < len(args)-1:
+			o.periph = args[i+1]
+			fwd = true
+		case strings.HasPrefix(v, `--peripheral=`):
+			o.periph = strings.TrimPrefix(v, `--peripheral=`)
+		case v == `-pkg` && i < len(args)-1:
+			o.pkg = args[i+1]
+			fwd = true
+		case v == `-o` && i < len(args)-1:
+			out = args[i+1]
+			fwd = true
+		default:
+			fns = append(fns, v)
+		}
+	}
+	if len(fns) != 1 {
+		prErr(`Error: import needs a single file to read!`, false)
+		usage()
+	}
+	src, ps, err := imp(fns[0], &o)
+	if err != nil {
+		prErr(fmt.Sprintf("Can not import: %s", err), false)
+		os.Exit(1)
+	}
+	var b bytes.Buffer
+	if len(o.pkg) == 0 {
+		o.pkg = `main`
+	}
+	fmt.Fprintf(&b, "// Code generated by bplint import %s; DO NOT EDIT.\n\npackage %s\n\n", format, o.pkg)
+	fmt.Fprintf(&b, "// Picstrings of %s.\nconst (\n", src)
+	for _, p := range ps {
+		if _, ds := check(p.pic, p.width, nil, num); len(ds) > 0 {
+			prErr(fmt.Sprintf("Error: %s: made %q that does not lint clean: %s [%s]",
+				p.name, p.pic, ds[0].Msg, ds[0].Rule), false)
+			errcnt++
+			continue
+		}
+		fmt.Fprintf(&b, "\t//bitpeek:%s width=%d\n\t%s = `%s`\n", p.name, p.width, ident(p.name), p.pic)
+	}
+	b.WriteString(")\n")
+	if len(out) == 0 {
+		_, err = os.Stdout.Write(b.Bytes())
+	} else {
+		err = os.WriteFile(out, b.Bytes(), 0644)
+	}
+	if err != nil {
+		prErr(fmt.Sprintf("Can not %s", err), false)
+		errcnt++
+	}
+	if errcnt > 0 {
+		os.Exit(1)
+	}
+}
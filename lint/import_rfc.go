@@ -0,0 +1,171 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rfcErr is a diagram error at line and column, both 1-based.
+type rfcErr struct {
+	fn        string
+	line, col int
+	msg       string
+}
+
+func (e *rfcErr) Error() string { return fmt.Sprintf("%s:%d:%d: %s", e.fn, e.line, e.col, e.msg) }
+
+// rfcBorder tells whether line is a +-+-+ row border, and where it starts.
+func rfcBorder(line string) (int, bool) {
+	t := strings.TrimSpace(line)
+	if !strings.HasPrefix(t, `+-`) || strings.Trim(t, `+-`) != `` {
+		return 0, false
+	}
+	return strings.IndexByte(line, '+'), true
+}
+
+// rfcSkip tells whether a box name stands for bits not used.
+func rfcSkip(name string) bool {
+	switch strings.ToLower(name) {
+	case ``, `reserved`, `unused`, `padding`, `pad`, `must be zero`, `mbz`, `zero`:
+		return true
+	}
+	return false
+}
+
+// rfcDiagram parses a diagram of 32 bit rows starting with the border
+// at lines[at]. It returns its picstring, width in bits and the index
+// of the line past the diagram.
+func rfcDiagram(fn string, lines []string, at int) (pic string, width, next int, err error) {
+	type box struct {
+		name []string
+		w    int
+	}
+	var boxes []box
+	c0, _ := rfcBorder(lines[at])
+	i := at
+	bad := func(line, col int, f string, a ...interface{}) error {
+		return &rfcErr{fn, line + 1, col + 1, fmt.Sprintf(f, a...)}
+	}
+	for rows := 0; ; rows++ {
+		b := strings.TrimRight(lines[i], " \t\r")
+		if len(b) != c0+65 {
+			return ``, 0, 0, bad(i, len(b)-1, "row border is %d bits, expected 32", (len(b)-c0-1)/2)
+		}
+		if i+1 >= len(lines) || !strings.HasPrefix(strings.TrimSpace(lines[i+1]), `|`) {
+			next = i + 1
+			break
+		}
+		if rows == 2 {
+			return ``, 0, 0, bad(i+1, c0, "more than two 32 bit rows")
+		}
+		width += 32
+		var edges []int
+		first := len(boxes)
+		for i++; i < len(lines); i++ {
+			l := strings.TrimRight(lines[i], " \t\r")
+			if _, ok := rfcBorder(l); ok {
+				break
+			}
+			if len(l) <= c0 || l[c0] != '|' {
+				return ``, 0, 0, bad(i, c0, "box line does not start at the border")
+			}
+			if edges == nil { // first line of the row sets box edges
+				for j := c0; j < len(l); j++ {
+					if l[j] != '|' {
+						continue
+					}
+					if (j-c0)%2 != 0 {
+						return ``, 0, 0, bad(i, j, "box edge is not on a bit boundary")
+					}
+					edges = append(edges, j)
+				}
+				if e := edges[len(edges)-1]; e != c0+64 {
+					return ``, 0, 0, bad(i, e, "box ends at bit %d, row needs 32", (e-c0)/2)
+				}
+				for k := 1; k < len(edges); k++ {
+					boxes = append(boxes, box{w: (edges[k] - edges[k-1]) / 2})
+				}
+			}
+			if len(l) != c0+65 {
+				return ``, 0, 0, bad(i, len(l)-1, "box line ends off the row end")
+			}
+			for k := 1; k < len(edges); k++ {
+				if l[edges[k]] != '|' {
+					return ``, 0, 0, bad(i, edges[k], "box edge missing")
+				}
+				if t := strings.TrimSpace(l[edges[k-1]+1 : edges[k]]); len(t) > 0 {
+					bx := &boxes[first+k-1]
+					bx.name = append(bx.name, t)
+				}
+			}
+		}
+		if i >= len(lines) {
+			return ``, 0, 0, bad(i-1, c0, "row has no closing border")
+		}
+	}
+	if width == 0 {
+		return ``, 0, 0, bad(at, c0, "border with no boxes below")
+	}
+	var s strings.Builder
+	skip := 0
+	for _, b := range boxes {
+		sep := ` `
+		if b.w == 1 { // letters stacked in a narrow box
+			sep = ``
+		}
+		name := strings.Join(b.name, sep)
+		if rfcSkip(name) {
+			skip += b.w
+			continue
+		}
+		if skip > 0 {
+			s.WriteString(skipPic(skip))
+			skip = 0
+		}
+		s.WriteString(picField(name, b.w))
+	}
+	if skip > 0 {
+		s.WriteString(skipPic(skip))
+	}
+	return strings.TrimPrefix(s.String(), ` `), width, next, nil
+}
+
+// importRFC makes picstrings of RFC style +-+-+ packet diagrams of the
+// file, one for each. They are named by the file, eg. ipv4.txt gives
+// ipv4, ipv4_2 for the second one.
+func importRFC(fn string, o *importOpts) (src string, ps []imported, err error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return
+	}
+	base := strings.TrimSuffix(filepath.Base(fn), filepath.Ext(fn))
+	lines := strings.Split(string(b), "\n")
+	for i := 0; i < len(lines); i++ {
+		if _, ok := rfcBorder(lines[i]); !ok {
+			continue
+		}
+		pic, w, next, err := rfcDiagram(fn, lines, i)
+		if err != nil {
+			return ``, nil, err
+		}
+		name := base
+		if len(ps) > 0 {
+			name = fmt.Sprintf("%s_%d", base, len(ps)+1)
+		}
+		ps = append(ps, imported{name, pic, w})
+		i = next - 1
+	}
+	if len(ps) == 0 {
+		return ``, nil, fmt.Errorf("%s: no +-+-+ diagram found", fn)
+	}
+	if len(o.pkg) == 0 {
+		o.pkg = strings.ToLower(ident(base))
+	}
+	return fmt.Sprintf("packet diagrams of %s", fn), ps, nil
+}
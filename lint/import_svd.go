@@ -0,0 +1,189 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CMSIS-SVD, as much of it as picstrings need.
+type svdDevice struct {
+	Name    string      `xml:"name"`
+	Size    string      `xml:"size"`
+	Periphs []svdPeriph `xml:"peripherals>peripheral"`
+}
+
+type svdPeriph struct {
+	Name        string        `xml:"name"`
+	DerivedFrom string        `xml:"derivedFrom,attr"`
+	Size        string        `xml:"size"`
+	Regs        []svdRegister `xml:"registers>register"`
+	Clusters    []svdCluster  `xml:"registers>cluster"`
+}
+
+type svdCluster struct {
+	Name string        `xml:"name"`
+	Regs []svdRegister `xml:"register"`
+}
+
+type svdRegister struct {
+	Name   string     `xml:"name"`
+	Size   string     `xml:"size"`
+	Fields []svdField `xml:"fields>field"`
+}
+
+type svdField struct {
+	Name      string `xml:"name"`
+	BitOffset string `xml:"bitOffset"`
+	BitWidth  string `xml:"bitWidth"`
+	Lsb       string `xml:"lsb"`
+	Msb       string `xml:"msb"`
+	BitRange  string `xml:"bitRange"`
+}
+
+// svdNum parses SVD scaledNonNegativeInteger, as far as bit numbers go.
+func svdNum(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `#`) {
+		n, err := strconv.ParseInt(s[1:], 2, 64)
+		return int(n), err
+	}
+	n, err := strconv.ParseInt(s, 0, 64)
+	return int(n), err
+}
+
+// bits returns lowest bit and width of a field given in any of three
+// SVD forms.
+func (f *svdField) bits() (lo, w int, err error) {
+	switch {
+	case len(f.BitRange) > 0:
+		var hi int
+		if _, err = fmt.Sscanf(strings.TrimSpace(f.BitRange), "[%d:%d]", &hi, &lo); err == nil {
+			w = hi - lo + 1
+		}
+	case len(f.Lsb) > 0:
+		var hi int
+		if lo, err = svdNum(f.Lsb); err == nil {
+			hi, err = svdNum(f.Msb)
+			w = hi - lo + 1
+		}
+	default:
+		if lo, err = svdNum(f.BitOffset); err == nil {
+			w = 1
+			if len(f.BitWidth) > 0 {
+				w, err = svdNum(f.BitWidth)
+			}
+		}
+	}
+	if err == nil && (lo < 0 || w < 1) {
+		err = fmt.Errorf("bad bit range")
+	}
+	return
+}
+
+// svdPic makes picstring of a register: fields from the most significant
+// one, gaps between them as skips. Bits above the top field are left out,
+// marker's width tells the register size.
+func svdPic(r *svdRegister, size int) (string, error) {
+	type fld struct {
+		name  string
+		lo, w int
+	}
+	var fs []fld
+	for _, f := range r.Fields {
+		lo, w, err := f.bits()
+		if err != nil {
+			return ``, fmt.Errorf("field %s: %s", f.Name, err)
+		}
+		if lo+w > size {
+			return ``, fmt.Errorf("field %s: bits %d..%d do not fit %d bit register", f.Name, lo+w-1, lo, size)
+		}
+		fs = append(fs, fld{f.Name, lo, w})
+	}
+	sort.Slice(fs, func(i, j int) bool { return fs[i].lo > fs[j].lo })
+	var b strings.Builder
+	for i, f := range fs {
+		if i > 0 && fs[i-1].lo < f.lo+f.w {
+			return ``, fmt.Errorf("fields %s and %s overlap", fs[i-1].name, f.name)
+		}
+		if i > 0 && fs[i-1].lo > f.lo+f.w {
+			b.WriteString(skipPic(fs[i-1].lo - f.lo - f.w))
+		}
+		b.WriteString(picField(f.name, f.w))
+	}
+	if n := len(fs); n > 0 && fs[n-1].lo > 0 {
+		b.WriteString(skipPic(fs[n-1].lo))
+	}
+	return strings.TrimPrefix(b.String(), ` `), nil
+}
+
+// importSVD makes picstrings of registers of the --peripheral.
+func importSVD(fn string, o *importOpts) (src string, ps []imported, err error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return
+	}
+	var dev svdDevice
+	if err = xml.Unmarshal(b, &dev); err != nil {
+		return ``, nil, fmt.Errorf("%s: %s", fn, err)
+	}
+	if len(o.periph) == 0 {
+		return ``, nil, fmt.Errorf("svd needs --peripheral NAME")
+	}
+	var p *svdPeriph
+	byName := map[string]*svdPeriph{}
+	for i := range dev.Periphs {
+		byName[dev.Periphs[i].Name] = &dev.Periphs[i]
+	}
+	if p = byName[o.periph]; p == nil {
+		return ``, nil, fmt.Errorf("%s: no peripheral %s", fn, o.periph)
+	}
+	regs, cls := p.Regs, p.Clusters
+	if d := byName[p.DerivedFrom]; len(regs) == 0 && len(cls) == 0 && d != nil {
+		regs, cls = d.Regs, d.Clusters
+	}
+	for _, c := range cls {
+		for _, r := range c.Regs {
+			r.Name = c.Name + `_` + r.Name
+			regs = append(regs, r)
+		}
+	}
+	dsize := 32
+	for _, s := range []string{dev.Size, p.Size} {
+		if len(s) > 0 {
+			if dsize, err = svdNum(s); err != nil {
+				return ``, nil, fmt.Errorf("%s: bad size %q", o.periph, s)
+			}
+		}
+	}
+	dim := strings.NewReplacer(`[%s]`, ``, `%s`, ``)
+	for i := range regs {
+		r := &regs[i]
+		name := o.periph + `/` + dim.Replace(r.Name)
+		size := dsize
+		if len(r.Size) > 0 {
+			if size, err = svdNum(r.Size); err != nil {
+				return ``, nil, fmt.Errorf("%s: bad size %q", name, r.Size)
+			}
+		}
+		if size < 1 || size > 64 {
+			return ``, nil, fmt.Errorf("%s: size %d is not 1..64", name, size)
+		}
+		pic, err := svdPic(r, size)
+		if err != nil {
+			return ``, nil, fmt.Errorf("%s: %s", name, err)
+		}
+		ps = append(ps, imported{name, pic, size})
+	}
+	if len(o.pkg) == 0 {
+		o.pkg = strings.ToLower(ident(o.periph))
+	}
+	return fmt.Sprintf("%s registers of %s, from %s", o.periph, dev.Name, fn), ps, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import "testing"
+
+func TestHexPic(t *testing.T) {
+	for w, v := range []string{``, `B`, `E`, `F`, `H`, `BH`, `EH`, `FH`, `HH`, `BHH`, `EHH`, `FHH`} {
+		if r := hexPic(w); r != v {
+			t.Errorf("hexPic(%d) = %s, expected %s", w, r, v)
+		}
+		if _, ds := Check(`x:` + hexPic(w)); w > 1 && len(ds) > 0 {
+			t.Errorf("hexPic(%d) does not lint clean: %v", w, ds)
+		}
+	}
+}
+
+func TestImportSVD(t *testing.T) {
+	want := []imported{
+		{`UART1/CR`, `'BAUD':HHH !06@ 'RXE= 'TXE= !07@ 'UARTEN=`, 32},
+		{`UART1/FR`, `'MODE':E 'LEVEL':FH 'BUSY= !03@`, 16},
+	}
+	o := importOpts{periph: `UART1`} // derived from UART0
+	_, ps, err := importSVD(`testdata/uart.svd`, &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("got %d registers, expected %d", len(ps), len(want))
+	}
+	for i, p := range ps {
+		if p != want[i] {
+			t.Errorf("got %v, expected %v", p, want[i])
+		}
+		if _, ds := check(p.pic, p.width, nil, Numbering{}); len(ds) > 0 {
+			t.Errorf("%s does not lint clean: %v", p.name, ds)
+		}
+	}
+	if o.pkg != `uart1` {
+		t.Errorf("package is %s", o.pkg)
+	}
+	if _, _, err := importSVD(`testdata/uart.svd`, &importOpts{periph: `SPI`}); err == nil {
+		t.Errorf("no error for missing peripheral")
+	}
+}
+
+func TestImportRFC(t *testing.T) {
+	want := []imported{
+		{`ipv4`, `'Version':H 'IHL':H 'Type of Service':HH 'Total Length':HHHH ` +
+			`'Identification (not done)':HHHH 'R0= 'DF= 'MF= 'Fragment Offset':BHHH`, 64},
+		{`ipv4_2`, `'Type':H 'A= !06@ 'Length':BHHHHH`, 32},
+	}
+	_, ps, err := importRFC(`testdata/ipv4.txt`, &importOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("got %d diagrams, expected %d", len(ps), len(want))
+	}
+	for i, p := range ps {
+		if p != want[i] {
+			t.Errorf("got %v, expected %v", p, want[i])
+		}
+	}
+	_, _, err = importRFC(`testdata/badbox.txt`, &importOpts{})
+	if e, ok := err.(*rfcErr); !ok || e.line != 2 || e.col != 13 {
+		t.Errorf("bad box error: %v", err)
+	}
+}
@@ -2,7 +2,7 @@
 // Use of this source code is governed by a MIT
 // license that can be found in the LICENSE file.
 
-package main
+package lint
 
 import (
 	//"fmt"
@@ -243,3 +243,51 @@ func TestLint(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestLintSegs(t *testing.T) {
+	l, ds := Check(lintTests[0].pic)
+	want := [][]string{{
+		`bits:|63 3b 61|    60|   59|58 11b 48|`,
+		`             ^      ^     ^         ^|`,
+		`cmds:¨¨Type:'F¨ 'EXT=¨.ACK=¨ Id:0xFHH¨`}, {
+		`bits:|47..     32b     ..16|15 16b 0|`,
+		`                          ^        ^|`,
+		`cmds:¨ from IPv4.Address32@¨¨¨:D.16@¨`}}
+	_, segs := lintSegs(l, ds, utf8R, 40, 0)
+	if len(segs) != len(want) {
+		t.Fatalf("got %d segments, want %d: %q", len(segs), len(want), segs)
+	}
+	for i := range want {
+		for k := range want[i] {
+			if segs[i][k] != want[i][k] {
+				t.Errorf("segment %d row %d:\n%q\nwant\n%q", i, k, segs[i][k], want[i][k])
+			}
+		}
+	}
+	if _, segs := lintSegs(l, ds, utf8R, 10, 0); len(segs) != 6 {
+		t.Errorf("narrow width: got %d segments, want one a field", len(segs))
+	}
+	if _, segs := lintSegs(l, ds, utf8R, 0, 0); len(segs) != 1 || segs[0][0] != lintTests[0].out[1] {
+		t.Errorf("no width: got %q", segs)
+	}
+}
+
+func TestRulers(t *testing.T) {
+	l, ds := Check(lintTests[0].pic)
+	want := []string{
+		`byte:|7       |7     |7    |7..6~~~~~|5..2                 |1..0    |`,
+		`nibl:|15      |15    |14   |14..12~~~|11..4                |3..0    |`,
+		lintTests[0].out[1], lintTests[0].out[2], lintTests[0].out[3]}
+	_, segs := lintSegs(l, ds, utf8R, 0, 2)
+	if len(segs) != 1 || len(segs[0]) != len(want) {
+		t.Fatalf("got %q", segs)
+	}
+	for k := range want {
+		if segs[0][k] != want[k] {
+			t.Errorf("row %d:\n%q\nwant\n%q", k, segs[0][k], want[k])
+		}
+	}
+	if _, segs := lintSegs(l, ds, utf8R, 40, 1); len(segs) != 2 || segs[1][0] != `byte:|5..2                 |1..0    |` {
+		t.Errorf("wrapped byte ruler: %q", segs)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	ts "text/scanner"
+)
+
+// mapFormats by the name given with map --format. Text ones are drawn
+// by the renderer given. The html format makes a single report of all
+// picstrings, see mapHTML.
+var mapFormats = map[string]func(l *Layout, r renderer) string{
+	`bits`:     bitsMap,
+	`rfc`:      plain(RFCDiagram),
+	`svg`:      plain(SVGDiagram),
+	`html`:     nil,
+	`md`:       plain(MarkdownTable),
+	`csv`:      plain(CSVTable),
+	`adoc`:     plain(AsciiDocTable),
+	`vertical`: verticalMap,
+}
+
+// plain makes a map format of a renderer that draws no terminal text.
+func plain(f func(l *Layout) string) func(l *Layout, r renderer) string {
+	return func(l *Layout, _ renderer) string { return f(l) }
+}
+
+// bitsMap is the bits: and cmds: map that check prints.
+func bitsMap(l *Layout, r renderer) string {
+	var b strings.Builder
+	_, segs := lintSegs(l, nil, r, termWidth(), rulers)
+	for _, s := range segs {
+		b.WriteString(strings.Join(s, "\n") + "\n")
+	}
+	return b.String()
+}
+
+// mapEntry is a marked picstring to render.
+type mapEntry struct {
+	tag string
+	pos ts.Position
+	pic string
+	l   *Layout
+	ds  []Diagnostic
+}
+
+// goFiles returns fn, or go files under fn if it is a directory.
+// Hidden, vendor and testdata directories are not looked into.
+func goFiles(fn string) (r []string, err error) {
+	if fi, err := os.Stat(fn); err != nil || !fi.IsDir() {
+		return []string{fn}, nil
+	}
+	err = filepath.WalkDir(fn, func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir() && p != fn && (strings.HasPrefix(d.Name(), `.`) || d.Name() == `vendor` || d.Name() == `testdata`):
+			return filepath.SkipDir
+		case !d.IsDir() && strings.HasSuffix(p, `.go`):
+			r = append(r, p)
+		}
+		return nil
+	})
+	return
+}
+
+// runMap is the 'bplint map' command. It renders maps of marked
+// picstrings that check clean, others get their findings printed.
+// Directories given are walked for go files.
+func runMap(args []string) {
+	format, out := `bits`, ``
+	rnd = autoRenderer()
+	var es []mapEntry
+	fwd := false
+	for i, v := range args {
+		switch {
+		case fwd:
+			fwd = false
+			continue
+		case (v == `-bits` || v == `-endian`) && i < len(args)-1:
+			setNum(v, args[i+1])
+			fwd = true
+		case v == `-m` && i < len(args)-1:
+			match = args[i+1]
+			fwd = true
+		case v == `-o` && i < len(args)-1:
+			out = args[i+1]
+			fwd = true
+		case v == `--format` && i < len(args)-1:
+			format = args[i+1]
+			fwd = true
+		case strings.HasPrefix(v, `--format=`):
+			format = strings.TrimPrefix(v, `--format=`)
+		case v == `--render` && i < len(args)-1:
+			setRender(args[i+1])
+			fwd = true
+		case strings.HasPrefix(v, `--render=`):
+			setRender(strings.TrimPrefix(v, `--render=`))
+		case v == `-width` && i < len(args)-1:
+			setWidth(args[i+1])
+			fwd = true
+		case v == `-ruler` && i < len(args)-1:
+			setRuler(args[i+1])
+			fwd = true
+		default:
+			fns, err := goFiles(v)
+			if err != nil {
+				prErr(fmt.Sprintf("Can not %s", err), false)
+				errcnt++
+			}
+			for _, fn := range fns {
+				es = append(es, mapFile(fn)...)
+			}
+		}
+	}
+	mf, ok := mapFormats[format]
+	if !ok {
+		var fs []string
+		for k := range mapFormats {
+			fs = append(fs, k)
+		}
+		sort.Strings(fs)
+		prErr(fmt.Sprintf("Error: unknown --format %s, use one of: %s", format, strings.Join(fs, `, `)), false)
+		os.Exit(1)
+	}
+	if files == 0 {
+		prErr(`Error: no files given and/or no files checked!`, false)
+		usage()
+	}
+	if len(out) > 0 && rnd == ansiR {
+		rnd = utf8R // no escapes in files
+	}
+	if len(out) > 0 && wrapAt < 0 {
+		wrapAt = 0 // nor terminal wrapping
+	}
+	var err error
+	switch {
+	case mf == nil: // html
+		r := mapHTML(es)
+		if len(out) == 0 {
+			_, err = os.Stdout.WriteString(r)
+		} else {
+			err = os.WriteFile(out, []byte(r), 0644)
+		}
+	case len(out) > 0: // a file per picstring in out directory
+		if err = os.MkdirAll(out, 0755); err != nil {
+			break
+		}
+		for i, e := range es {
+			if len(e.ds) == 0 {
+				fn := filepath.Join(out, fmt.Sprintf("%s.%s", fileTag(e.tag, i), format))
+				if err = os.WriteFile(fn, []byte(mf(e.l, rnd)), 0644); err != nil {
+					break
+				}
+			}
+		}
+	default:
+		for _, e := range es {
+			fmt.Printf("--- Pic: \"%s\" in %s line %d -\n", e.tag, e.pos.Filename, e.pos.Line)
+			if len(e.ds) > 0 {
+				prDiags(e.ds)
+			} else {
+				fmt.Print(mf(e.l, rnd))
+			}
+			fmt.Println()
+		}
+	}
+	if err != nil {
+		prErr(fmt.Sprintf("Can not %s", err), false)
+		errcnt++
+	}
+	if errcnt > 0 {
+		os.Exit(1)
+	}
+}
+
+// fileTag makes a file name of the i-th picstring tag.
+func fileTag(tag string, i int) string {
+	return fmt.Sprintf("%03d-%s", i+1, strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, tag))
+}
+
+// mapFile collects marked picstrings of a file matching -m. Ones that
+// do not check clean are counted as errors.
+func mapFile(fn string) (es []mapEntry) {
+	ps, fsup, err := scanFile(fn)
+	if err != nil {
+		prErr(fmt.Sprintf("Can not %s", err), false)
+		errcnt++
+		return
+	}
+	files++
+	for i := range ps {
+		p := &ps[i]
+		if p.bad != nil || !strings.Contains(p.tag, match) {
+			continue
+		}
+		l, ds := checkMarked(fn, p, fsup)
+		if len(ds) > 0 {
+			errcnt++
+		}
+		es = append(es, mapEntry{p.tag, p.pos, p.pic, l, ds})
+	}
+	return
+}
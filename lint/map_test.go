@@ -0,0 +1,22 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import "testing"
+
+// Map takes nolints as check does, picstrings check passes are drawn.
+func TestMapNolint(t *testing.T) {
+	defer func(n int) { errcnt = n }(errcnt)
+	errcnt = 0
+	es := mapFile(`testdata/nolint.go`)
+	if len(es) != 4 || errcnt != 0 {
+		t.Fatalf("got %d entries, %d errors; expected 4, 0", len(es), errcnt)
+	}
+	for _, e := range es {
+		if len(e.ds) > 0 {
+			t.Errorf("%s: %s [%s]", e.tag, e.ds[0].Msg, e.ds[0].Rule)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	ts "text/scanner"
+)
+
+// marked is a picstring found in a source file under a //bitpeek marker.
+type marked struct {
+	tag  string
+	pic  string // as written, without quotes
+	pos  ts.Position
+	sup  *suppress   // nolint given to this picstring
+	mk   *marker     // marker it was found by
+	bad  *Diagnostic // finding about the marker at pos, no picstring then
+	last bool        // last user of sup, report unused nolint here
+}
+
+// marker is a parsed //bitpeek comment. Legacy form is
+//
+//	//bitpeek:tag:skip[:attr...]
+//
+// with skip a single digit 0..7, as older bplint read just that, and
+// attributes may also follow the tag after a space:
+//
+//	//bitpeek:tag skip=12 width=32 field=Pic nolint=BP002 lang=pl masks=Hdr*
+//
+// Region markers //bitpeek:begin tag [attr...] and //bitpeek:end
+// enclose elements of a table literal, see region.
+type marker struct {
+	tag    string
+	skip   int    // strings to skip
+	width  int    // declared width in bits
+	field  string // key of the picstring in a composite literal
+	lang   string // language of labels
+	masks  string // pattern of constant names, see maskSet
+	nolint *suppress
+	pos    ts.Position
+	begin  bool // of region
+	end    bool // of region
+}
+
+// parseMarker parses text of a //bitpeek comment. It returns nil marker
+// and no error if the comment is not a marker at all.
+func parseMarker(text string, pos ts.Position) (*marker, error) {
+	if !strings.HasPrefix(text, `//bitpeek`) {
+		return nil, nil
+	}
+	t := text[9:]
+	if len(t) > 0 && t[0] != ':' {
+		return nil, nil
+	}
+	mk := &marker{tag: `unnamed`, width: 64, pos: pos}
+	if len(t) == 0 {
+		return mk, nil
+	}
+	var attrs []string
+	ws := strings.Fields(t[1:])
+	if cs := strings.Split(append(ws, ``)[0], ":"); strings.IndexByte(cs[0], '=') < 0 {
+		// tag:skip:attr...
+		if len(cs[0]) > 0 {
+			mk.tag = cs[0]
+		}
+		if len(cs) > 1 {
+			if n, err := strconv.Atoi(cs[1]); err == nil && (len(cs[1]) > 1 || n > 7) {
+				old := 0 // first digit 0..7 as once read
+				if cs[1][0]|7 == '7' {
+					old = int(cs[1][0] - '0')
+				}
+				return nil, fmt.Errorf("Legacy skip :%s was once read as :%d, write skip=%d or :%d.", cs[1], old, n, old)
+			}
+			attrs = append(attrs, `skip=`+cs[1])
+		}
+		attrs = append(attrs, cs[min(len(cs), 2):]...)
+		if len(ws) > 0 {
+			ws = ws[1:]
+		}
+	}
+	switch {
+	case len(attrs) > 0:
+	case mk.tag == `end`:
+		if len(ws) > 0 {
+			return nil, fmt.Errorf("Region end takes no attributes.")
+		}
+		mk.end = true
+		return mk, nil
+	case mk.tag == `begin`:
+		mk.begin = true
+		mk.tag = `unnamed`
+		if len(ws) > 0 && strings.IndexByte(ws[0], '=') < 0 {
+			mk.tag = ws[0]
+			ws = ws[1:]
+		}
+	}
+	attrs = append(attrs, ws...)
+	seen := map[string]bool{}
+	for _, a := range attrs {
+		k, v, hasv := strings.Cut(a, `=`)
+		if seen[k] {
+			return nil, fmt.Errorf("Marker attribute %s given twice.", k)
+		}
+		seen[k] = true
+		var err error
+		switch k {
+		case `skip`:
+			mk.skip, err = mkNumber(k, v, 0, 999)
+		case `width`:
+			mk.width, err = mkNumber(k, v, 1, 64)
+		case `field`:
+			if len(v) == 0 {
+				err = fmt.Errorf("Marker attribute field needs a value.")
+			}
+			mk.field = v
+		case `masks`:
+			if _, err = path.Match(v, ``); err != nil || len(v) == 0 {
+				err = fmt.Errorf("Bad marker attribute masks=%s, need a name pattern.", v)
+			}
+			mk.masks = v
+		case `lang`:
+			if len(v) == 0 || strings.Trim(v, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ-_") != `` {
+				err = fmt.Errorf("Bad marker attribute lang=%s.", v)
+			}
+			mk.lang = v
+		case `nolint`:
+			if hasv && len(v) == 0 {
+				err = fmt.Errorf("Marker attribute nolint= needs rule IDs.")
+			}
+			mk.nolint = newSuppress(v, pos, false)
+		default:
+			err = fmt.Errorf("Unknown marker attribute %q.", a)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return mk, nil
+}
+
+func mkNumber(k, v string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("Bad marker attribute %s=%s, need a number %d..%d.", k, v, lo, hi)
+	}
+	return n, nil
+}
+
+// suppress holds rule IDs given with nolint. Empty ids stand for all rules.
+type suppress struct {
+	pos   ts.Position
+	ids   []string
+	used  []bool
+	named bool // lists IDs, is not a bare //nolint of all linters
+}
+
+// newSuppress parses comma separated list of rule IDs. Word "bplint"
+// stands for all rules, words that are not IDs of registered rules
+// are ignored if lax is set - they may belong to other linters.
+func newSuppress(list string, pos ts.Position, lax bool) *suppress {
+	s := &suppress{pos: pos, named: len(strings.TrimSpace(list)) > 0}
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimSpace(v)
+		switch {
+		case len(v) == 0:
+		case v == `bplint`:
+			s.ids = append(s.ids, ``)
+		case lax && !isRule(v):
+		default:
+			s.ids = append(s.ids, v)
+		}
+	}
+	if len(s.ids) == 0 && len(strings.TrimSpace(list)) == 0 {
+		s.ids = append(s.ids, ``)
+	}
+	if len(s.ids) == 0 {
+		return nil
+	}
+	s.used = make([]bool, len(s.ids))
+	return s
+}
+
+// merge adds IDs of o to s.
+func (s *suppress) merge(o *suppress) *suppress {
+	if s == nil {
+		return o
+	}
+	if o != nil {
+		s.ids = append(s.ids, o.ids...)
+		s.used = append(s.used, o.used...)
+		s.named = s.named || o.named
+	}
+	return s
+}
+
+// covers tells whether diagnostics of rule id are suppressed, then
+// marks suppression as used.
+func (s *suppress) covers(id string) bool {
+	if s == nil {
+		return false
+	}
+	for i, v := range s.ids {
+		if v == id || len(v) == 0 {
+			s.used[i] = true
+			return true
+		}
+	}
+	return false
+}
+
+// unused returns rule IDs that suppressed nothing, "bplint" for all.
+func (s *suppress) unused() (r []string) {
+	if s == nil {
+		return
+	}
+	for i, v := range s.ids {
+		if s.used[i] {
+			continue
+		}
+		if len(v) == 0 {
+			v = `bplint`
+		}
+		r = append(r, v)
+	}
+	return
+}
+
+func isRule(id string) bool {
+	for _, r := range rules {
+		if r.ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// region is a //bitpeek:begin ... //bitpeek:end block of a table
+// literal. In every element of the table, a {...} opened at the top
+// level of the region, picstring is the field=N string of the element
+// (second if not given) or the one keyed with field=Key. Element is
+// named by its first string.
+type region struct {
+	mk    *marker
+	sup   *suppress
+	skip  bool // not matching -m
+	depth int  // of brackets since begin
+	n     int  // strings of the element met
+	key   int  // field= key met: 1 name, 2 name and colon
+	nth   int  // field=N
+	name  string
+	at    ts.Position // element start
+	done  bool        // element picstring taken
+	found int         // picstrings taken
+}
+
+// token follows the region. It returns picstring of the current element
+// and true when it is met. Element without one is reported by bad.
+func (r *region) token(f *ts.Scanner, x rune, bad func(rule, msg string)) (string, bool) {
+	switch x {
+	case '(', '[', '{':
+		if r.depth++; r.depth == 1 && x == '{' {
+			r.n, r.key, r.name, r.done, r.at = 0, 0, ``, false, f.Position
+		}
+		return ``, false
+	case ')', ']', '}':
+		if r.depth--; r.depth == 0 && x == '}' && !r.done {
+			bad(`BP006`, fmt.Sprintf("Region element at line %d has no picstring.", r.at.Line))
+		}
+		return ``, false
+	}
+	if r.depth != 1 {
+		return ``, false
+	}
+	switch {
+	case r.nth == 0 && x == ts.Ident:
+		r.key = 0
+		if f.TokenText() == r.mk.field {
+			r.key = 1
+		}
+	case r.nth == 0 && x == ':' && r.key == 1:
+		r.key = 2
+	case x == ts.String || x == ts.RawString:
+		s := f.TokenText()
+		s = s[1 : len(s)-1]
+		if r.n++; r.n == 1 {
+			r.name = s
+		}
+		if !r.done && (r.n == r.nth || r.key == 2) {
+			r.done = true
+			r.found++
+			return s, true
+		}
+	default:
+		r.key = 0
+	}
+	return ``, false
+}
+
+// scanFile collects marked picstrings of a go source file. Returned
+// suppress comes from //nolint comments put before the package clause.
+//
+// Markers are:
+//
+//	//bitpeek:tag skip=1 nolint=BP002     see marker for all attributes
+//	//nolint:bplint or //nolint:BP002     nolint for the marker right below,
+//	                                      or whole file if before package
+//
+// A nolint that is followed by code before a marker, or trails code on
+// its line, reaches no picstring. It is returned as unused (BP004) then,
+// unless it is a bare //nolint meant for all linters.
+// With field=Key given, the string is found by srcFile.fieldAt.
+func scanFile(fn string) (ps []marked, fsup *suppress, err error) {
+	src, err := os.ReadFile(fn)
+	if err != nil {
+		return
+	}
+	sf := &srcFile{fn: fn, src: src}
+	var f ts.Scanner
+	f.Init(bytes.NewReader(src))
+	f.Filename = fn
+	f.Mode = ts.GoTokens &^ ts.SkipComments
+	f.Whitespace = ts.GoWhitespace &^ (1 << '\n') // for statement ends
+	skip := -1                                    // raw strings below to skip
+	want := -1                                    // offset of the string field= points at
+	depth := 0                                    // of brackets opened after marker
+	var last rune
+	var mk *marker
+	inpkg := false
+	var sup *suppress  // for the picstring of mk
+	var pend *suppress // nolint waiting for a marker
+	code := false      // line has tokens other than comments
+	var rg *region
+	orphan := func() { // pend reached no marker
+		if pend != nil && pend.named {
+			for _, v := range pend.unused() {
+				d := unusedNolint(v)
+				ps = append(ps, marked{pos: pend.pos, bad: &d})
+			}
+		}
+		pend = nil
+	}
+	bad := func(rule, msg string) { // marker went wrong
+		ps = append(ps, marked{tag: mk.tag, pos: mk.pos, mk: mk,
+			bad: &Diagnostic{Rule: rule, Msg: msg}})
+		skip = -1
+		sup = nil
+	}
+	rgbad := func(rule, msg string) {
+		if !rg.skip {
+			ps = append(ps, marked{tag: rg.mk.tag, pos: rg.mk.pos, mk: rg.mk,
+				bad: &Diagnostic{Rule: rule, Msg: msg}})
+		}
+	}
+	rgend := func() {
+		if rg.found == 0 {
+			rgbad(`BP006`, `Region matched no picstring.`)
+		}
+		for i := len(ps) - 1; i >= 0; i-- {
+			if ps[i].sup == rg.sup && ps[i].bad == nil {
+				ps[i].last = true
+				break
+			}
+		}
+		rg = nil
+	}
+	for x := f.Scan(); x != ts.EOF; x = f.Scan() {
+		switch {
+		case x == ts.Comment:
+		case x == '\n':
+			if code {
+				orphan()
+			}
+			code = false
+		default:
+			orphan()
+			code = true
+		}
+		if rg != nil && x != ts.Comment && x != '\n' {
+			if x == '}' && rg.depth == 0 {
+				rgbad(`BP007`, fmt.Sprintf("Region ran past the closing } at line %d.", f.Position.Line))
+				rgend()
+			} else if s, ok := rg.token(&f, x, rgbad); ok && !rg.skip &&
+				(len(ps) == 0 || ps[len(ps)-1].pos != f.Pos()) {
+				ps = append(ps, marked{rg.mk.tag + `/` + rg.name, s, f.Pos(), rg.sup, rg.mk, nil, false})
+			}
+		}
+		switch x {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth--; depth < 0 && skip >= 0 && want < 0 {
+				bad(`BP007`, fmt.Sprintf("Marker skip ran past the closing %c at line %d.", x, f.Position.Line))
+			}
+		case '\n':
+			if depth == 0 && skip >= 0 && want < 0 && endsStmt(last) {
+				bad(`BP007`, fmt.Sprintf("Marker skip ran past the end of statement at line %d.", f.Position.Line))
+			}
+			continue
+		}
+		switch {
+		case x == ts.Ident && !inpkg:
+			inpkg = true
+		case x == ts.Comment && strings.HasPrefix(f.TokenText(), `//nolint`):
+			t := f.TokenText()[8:]
+			if len(t) > 0 && t[0] != ':' {
+				continue
+			}
+			if len(t) > 0 {
+				t = t[1:]
+			}
+			switch {
+			case !inpkg:
+				fsup = fsup.merge(newSuppress(t, f.Position, true))
+			case skip >= 0: // between marker and its picstring
+				sup = sup.merge(newSuppress(t, f.Position, true))
+			default:
+				pend = pend.merge(newSuppress(t, f.Position, true))
+			}
+			continue
+		case x == ts.Comment: // //bitpeek:name:skip
+			m, err := parseMarker(f.TokenText(), f.Position)
+			if m == nil && err == nil {
+				if nearMarker(f.TokenText()) {
+					ps = append(ps, marked{pos: f.Position, bad: &Diagnostic{Rule: `BP008`,
+						Msg: fmt.Sprintf("%q looks like a misspelled //bitpeek marker.", f.TokenText())}})
+				}
+				continue
+			}
+			if skip >= 0 {
+				bad(`BP006`, `Marker matched no picstring.`)
+			}
+			switch {
+			case err == nil && m.begin && rg != nil:
+				err = fmt.Errorf("Region begins inside region of line %d.", rg.mk.pos.Line)
+			case err == nil && m.end && rg == nil:
+				err = fmt.Errorf("Region end without begin.")
+			}
+			switch {
+			case err != nil:
+				ps = append(ps, marked{pos: f.Position, bad: &Diagnostic{Rule: `BP005`, Msg: err.Error()}})
+				continue
+			case m.end:
+				rgend()
+				continue
+			case m.begin:
+				rg = &region{mk: m, sup: pend.merge(m.nolint), nth: 2}
+				pend = nil
+				if len(m.field) > 0 {
+					rg.nth, _ = strconv.Atoi(m.field) // 0 for keyed
+				}
+				if rg.skip = len(match) > 0 && strings.Index(m.tag, match) < 0; rg.skip {
+					filtered++
+				}
+				continue
+			case len(match) > 0 && strings.Index(m.tag, match) < 0:
+				filtered++
+				pend = nil
+				continue
+			}
+			mk = m
+			skip = m.skip
+			want = -1
+			depth = 0
+			last = 0
+			sup = pend.merge(m.nolint)
+			pend = nil
+			if len(m.field) > 0 {
+				skip = 0
+				if want, err = sf.fieldAt(f.Position.Offset+len(f.TokenText()), m.field, m.skip); err != nil {
+					want = -1
+					bad(`BP006`, err.Error())
+				}
+			}
+			continue
+		case skip < 0: // not ours at all
+		case want >= 0 && f.Position.Offset != want:
+		case x == ts.String && skip > 0:
+			skip--
+		case x == ts.RawString && skip > 0:
+			skip--
+		case x == ts.RawString || x == ts.String:
+			s := f.TokenText()
+			if n := len(ps); n > 0 && ps[n-1].pos == f.Pos() && ps[n-1].bad == nil {
+				ps = ps[:n-1] // marker wins over region
+			}
+			ps = append(ps, marked{mk.tag, s[1 : len(s)-1], f.Pos(), sup, mk, nil, true})
+			skip = -1
+			sup = nil
+		}
+		last = x
+	}
+	if skip >= 0 {
+		bad(`BP006`, `Marker matched no picstring.`)
+	}
+	orphan()
+	if rg != nil {
+		rgbad(`BP006`, `Region has no //bitpeek:end.`)
+		rgend()
+	}
+	return
+}
+
+// endsStmt tells whether newline after token x ends a go statement.
+func endsStmt(x rune) bool {
+	switch x {
+	case ts.Ident, ts.Int, ts.Float, ts.Char, ts.String, ts.RawString, ')', ']', '}':
+		return true
+	}
+	return false
+}
+
+// nearMarker tells whether comment looks like a misspelled marker,
+// eg. "// bitpeek:", "//bitpeak:tag" or "/*bitpeek*/".
+func nearMarker(text string) bool {
+	t := strings.TrimSuffix(text[2:], `*/`)
+	t = strings.TrimLeft(t, " \t")
+	if len(t) == 0 || t[0]|0x20 < 'a' || t[0]|0x20 > 'z' {
+		return false // eg. marker quoted in docs
+	}
+	w := t
+	if i := strings.IndexAny(t, ": \t"); i >= 0 {
+		if t[i] != ':' {
+			return false // prose
+		}
+		w = t[:i]
+	}
+	return editDist(strings.ToLower(w), `bitpeek`) <= 2
+}
+
+// editDist returns Levenshtein distance of a and b.
+func editDist(a, b string) int {
+	d := make([]int, len(b)+1)
+	for j := range d {
+		d[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		p := d[0]
+		d[0] = i
+		for j := 1; j <= len(b); j++ {
+			c := p
+			if a[i-1] != b[j-1] {
+				c = 1 + min(p, d[j-1], d[j])
+			}
+			p, d[j] = d[j], c
+		}
+	}
+	return d[len(b)]
+}
@@ -0,0 +1,249 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+	ts "text/scanner"
+)
+
+func TestNolint(t *testing.T) {
+	ps, fsup, err := scanFile(`testdata/nolint.go`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		tag    string
+		keep   string // rules of diagnostics left
+		unused string
+	}{
+		{`hex`, ``, ``},
+		{`two`, ``, `BP002`},
+		{`all`, ``, ``},
+		{`ok`, ``, ``},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("found %d picstrings, expected %d", len(ps), len(want))
+	}
+	for i, v := range want {
+		p := &ps[i]
+		_, ds := Check(p.pic)
+		var keep []string
+		for _, d := range ds {
+			if !p.sup.covers(d.Rule) && !fsup.covers(d.Rule) {
+				keep = append(keep, d.Rule)
+			}
+		}
+		k := strings.Join(keep, `,`)
+		u := strings.Join(p.sup.unused(), `,`)
+		if p.tag != v.tag || k != v.keep || u != v.unused {
+			t.Errorf("%s: got tag %s, left [%s] unused [%s]; expected [%s] [%s]",
+				v.tag, p.tag, k, u, v.keep, v.unused)
+		}
+	}
+	if u := fsup.unused(); len(u) != 0 {
+		t.Errorf("file nolint unused: %v", u)
+	}
+}
+
+// Nolint binds to the marker on the next line only, not to any further
+// down. Ones that reach no marker are reported unused.
+func TestNolintScope(t *testing.T) {
+	ps, _, err := scanFile(`testdata/nolintscope.go`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		tag, rule string
+		line      int
+	}{
+		{`late`, ``, 8},
+		{``, `BP004`, 10},
+		{``, `BP004`, 11},
+		{`near`, ``, 16},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("found %d, expected %d", len(ps), len(want))
+	}
+	for i, v := range want {
+		p := &ps[i]
+		rule := ``
+		if p.bad != nil {
+			rule = p.bad.Rule
+		}
+		if p.tag != v.tag || rule != v.rule || p.pos.Line != v.line {
+			t.Errorf("%d: got %q %q at line %d, expected %q %q at %d", i, p.tag, rule, p.pos.Line, v.tag, v.rule, v.line)
+		}
+	}
+	if _, ds := Check(ps[0].pic); ps[0].sup != nil || len(ds) == 0 {
+		t.Errorf("late: nolint from line 4 silenced %v", ds)
+	}
+	if !ps[3].sup.covers(`BP002`) {
+		t.Errorf("near: nolint of line 13 not applied")
+	}
+}
+
+var markerTests = []struct {
+	text  string
+	tag   string
+	skip  int
+	width int
+	field string
+	err   bool
+}{
+	{`//bitpeek`, `unnamed`, 0, 64, ``, false},
+	{`//bitpeek:`, `unnamed`, 0, 64, ``, false},
+	{`//bitpeek:Example:1`, `Example`, 1, 64, ``, false},
+	{`//bitpeek:Name`, `Name`, 0, 64, ``, false},
+	{`//bitpeek:tag:0:nolint=BP002`, `tag`, 0, 64, ``, false},
+	{`//bitpeek:tag skip=12 width=32 field=Pic nolint=BP002 lang=pl`, `tag`, 12, 32, `Pic`, false},
+	{`//bitpeek: skip=2`, `unnamed`, 2, 64, ``, false},
+	{`//bitpeek:tag:7`, `tag`, 7, 64, ``, false},
+	{`//bitpeek:tag:12`, ``, 0, 0, ``, true}, // once skip=1
+	{`//bitpeek:tag:8`, ``, 0, 0, ``, true},
+	{`//bitpeek:tag:`, ``, 0, 0, ``, true},
+	{`//bitpeek:tag:x`, ``, 0, 0, ``, true},
+	{`//bitpeek:tag width=65`, ``, 0, 0, ``, true},
+	{`//bitpeek:tag skip=1 skip=2`, ``, 0, 0, ``, true},
+	{`//bitpeek:tag:1 skip=2`, ``, 0, 0, ``, true},
+	{`//bitpeek:tag colour=red`, ``, 0, 0, ``, true},
+	{`//bitpeek:tag field=`, ``, 0, 0, ``, true},
+	{`//bitpeek:tag nolint=`, ``, 0, 0, ``, true},
+}
+
+func TestMarker(t *testing.T) {
+	for _, v := range markerTests {
+		mk, err := parseMarker(v.text, ts.Position{})
+		switch {
+		case v.err && err == nil:
+			t.Errorf("%s: expected error", v.text)
+		case v.err:
+		case err != nil:
+			t.Errorf("%s: %s", v.text, err)
+		case mk.tag != v.tag || mk.skip != v.skip || mk.width != v.width || mk.field != v.field:
+			t.Errorf("%s: got %s %d %d %s", v.text, mk.tag, mk.skip, mk.width, mk.field)
+		}
+	}
+	if mk, _ := parseMarker(`//bitpeeks:tag`, ts.Position{}); mk != nil {
+		t.Errorf("//bitpeeks taken as a marker")
+	}
+}
+
+func TestScanMarkers(t *testing.T) {
+	ps, _, err := scanFile(`testdata/markers.go`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ tag, pic string }{
+		{`keyed`, `Id:FHH`},
+		{`skip12`, `Mode:FH`},
+		{``, ``}, // bad marker
+		{`narrow`, `x`},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("found %d picstrings, expected %d", len(ps), len(want))
+	}
+	for i, v := range want {
+		if ps[i].tag != v.tag || ps[i].pic != v.pic || (ps[i].bad != nil) != (len(v.tag) == 0) {
+			t.Errorf("%d: got %s %q %v", i, ps[i].tag, ps[i].pic, ps[i].bad)
+		}
+	}
+	if _, ds := check(`HHH`, ps[3].mk.width, nil, Numbering{}); len(ds) != 1 || ds[0].Rule != `BP001` {
+		t.Errorf("width=8 not applied: %v", ds)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	ps, _, err := scanFile(`testdata/orphans.go`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		rule string
+		line int
+	}{
+		{`BP006`, 4},
+		{`BP007`, 5},
+		{`BP008`, 9},
+		{`BP008`, 12},
+		{`BP007`, 15},
+		{`BP008`, 21},
+		{`BP006`, 23},
+	}
+	if len(ps) != len(want) {
+		for _, p := range ps {
+			t.Logf("%d %v", p.pos.Line, p.bad)
+		}
+		t.Fatalf("found %d, expected %d", len(ps), len(want))
+	}
+	for i, v := range want {
+		if ps[i].bad == nil || ps[i].bad.Rule != v.rule || ps[i].pos.Line != v.line {
+			t.Errorf("%d: got %v at line %d, expected %s at %d", i, ps[i].bad, ps[i].pos.Line, v.rule, v.line)
+		}
+	}
+}
+
+func TestRegion(t *testing.T) {
+	ps, _, err := scanFile(`testdata/region.go`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ tag, pic, rule string }{
+		{`tbl/first`, `Id:FHH`, ``},
+		{`tbl/second`, `FFF`, ``},
+		{`own`, `Mode:BHH`, ``},
+		{`tbl`, ``, `BP006`}, // empty
+		{`kv/F`, `F`, ``},    // named by the first string
+		{`kv/two`, `HH`, ``},
+		{`kv`, ``, `BP007`}, // no end
+	}
+	if len(ps) != len(want) {
+		for _, p := range ps {
+			t.Logf("%s %q %v", p.tag, p.pic, p.bad)
+		}
+		t.Fatalf("found %d, expected %d", len(ps), len(want))
+	}
+	for i, v := range want {
+		p := ps[i]
+		if p.tag != v.tag || p.pic != v.pic || (p.bad == nil) != (len(v.rule) == 0) ||
+			p.bad != nil && p.bad.Rule != v.rule {
+			t.Errorf("%d: got %s %q %v", i, p.tag, p.pic, p.bad)
+		}
+	}
+	if !ps[1].last || ps[0].last {
+		t.Error("region nolint is to be checked at its last picstring")
+	}
+}
+
+func TestFieldMarker(t *testing.T) {
+	ps, _, err := scanFile(`testdata/fields.go`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ tag, pic, name string }{
+		{`multi`, `Id:FHH`, `Id`},
+		{`call`, `Mode:EH`, `Mode`},
+		{`nth`, `Nth:FH`, `Nth`},
+		{`alt`, `'Alt':BH`, `Alt`},
+		{`nested`, `Wrap:EH`, `Wrap`},
+		{`missing`, ``, ``}, // BP006
+	}
+	if len(ps) != len(want) {
+		for _, p := range ps {
+			t.Logf("%s %q %v", p.tag, p.pic, p.bad)
+		}
+		t.Fatalf("found %d, expected %d", len(ps), len(want))
+	}
+	for i, v := range want {
+		p := ps[i]
+		if p.tag != v.tag || p.pic != v.pic || (p.bad != nil) != (len(v.pic) == 0) {
+			t.Errorf("%d: got %s %q %v", i, p.tag, p.pic, p.bad)
+		}
+		if l, _ := check(p.pic, 64, nil, Numbering{}); len(v.pic) > 0 && strings.Join(l.Names(), ` `) != v.name {
+			t.Errorf("%d: %q has fields %v, expected %s", i, p.pic, l.Names(), v.name)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math/bits"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maskConst is a Mask and/or Shift constant pair of a field.
+type maskConst struct {
+	mask, shift  uint64
+	mname, sname string // constant names, empty if not defined
+}
+
+// maskSet holds constants picked by a masks= marker attribute, by the
+// field name they are for: HdrIdMask and HdrIdShift picked by Hdr* are
+// for the field named Id.
+type maskSet struct {
+	pat string
+	err error
+	by  map[string]*maskConst
+}
+
+// pkgConsts caches integer constants of type checked packages, by
+// directory and package name.
+var pkgConsts = map[string]map[string]uint64{}
+
+// loadConsts type checks the package of go file fn and returns its
+// package level integer constants. Type errors are ignored as long as
+// constants can be evaluated.
+func loadConsts(fn string) (map[string]uint64, error) {
+	fset := token.NewFileSet()
+	af, err := parser.ParseFile(fset, fn, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return nil, err
+	}
+	dir, test := filepath.Dir(fn), strings.HasSuffix(fn, `_test.go`)
+	key := dir + "\x00" + af.Name.Name
+	if test {
+		key += `_test`
+	}
+	if cs, ok := pkgConsts[key]; ok {
+		return cs, nil
+	}
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var afs []*ast.File
+	for _, de := range des {
+		n := de.Name()
+		if de.IsDir() || !strings.HasSuffix(n, `.go`) || !test && strings.HasSuffix(n, `_test.go`) {
+			continue
+		}
+		if ok, _ := build.Default.MatchFile(dir, n); !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, n), nil, parser.SkipObjectResolution)
+		if err != nil || f.Name.Name != af.Name.Name {
+			continue
+		}
+		afs = append(afs, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, `source`, nil), Error: func(error) {}}
+	pkg, _ := conf.Check(af.Name.Name, fset, afs, nil)
+	cs := map[string]uint64{}
+	if pkg != nil {
+		sc := pkg.Scope()
+		for _, n := range sc.Names() {
+			c, ok := sc.Lookup(n).(*types.Const)
+			if !ok || c.Val().Kind() != constant.Int {
+				continue
+			}
+			if v, exact := constant.Uint64Val(c.Val()); exact {
+				cs[n] = v
+			}
+		}
+	}
+	pkgConsts[key] = cs
+	return cs, nil
+}
+
+// newMaskSet picks constants of go file fn that match pattern pat.
+// Field name is what is left of the constant name after the part of pat
+// before the first * and the Mask or Shift suffix are cut off.
+func newMaskSet(fn, pat string) *maskSet {
+	ms := &maskSet{pat: pat, by: map[string]*maskConst{}}
+	cs, err := loadConsts(fn)
+	if err != nil {
+		ms.err = err
+		return ms
+	}
+	pfx, _, _ := strings.Cut(pat, `*`)
+	for n, v := range cs {
+		if ok, _ := path.Match(pat, n); !ok {
+			continue
+		}
+		fld, mask := strings.CutSuffix(n[len(pfx):], `Mask`)
+		if !mask {
+			var shift bool
+			if fld, shift = strings.CutSuffix(fld, `Shift`); !shift {
+				continue
+			}
+		}
+		mc := ms.by[fld]
+		if mc == nil {
+			mc = &maskConst{}
+			ms.by[fld] = mc
+		}
+		if mask {
+			mc.mask, mc.mname = v, n
+		} else {
+			mc.shift, mc.sname = v, n
+		}
+	}
+	return ms
+}
+
+func init() {
+	Register(NewRule(`BP009`, ckMasks))
+}
+
+// BP009: fields must take exactly the bits of their mask constants.
+// Mask may be given either in place or unshifted, then with the Shift.
+func ckMasks(l *Layout) (ds []Diagnostic) {
+	ms := l.masks
+	switch {
+	case ms == nil:
+		return nil
+	case ms.err != nil:
+		return []Diagnostic{{Msg: fmt.Sprintf("Can not read constants for masks=%s: %s", ms.pat, ms.err)}}
+	case len(ms.by) == 0:
+		return []Diagnostic{{Msg: fmt.Sprintf("Marker masks=%s matches no Mask nor Shift constants.", ms.pat)}}
+	}
+	names := l.Names()
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		mc := ms.by[ident(names[i])]
+		if f.Kind == KindSkip || mc == nil {
+			continue
+		}
+		fb := fmt.Sprintf("Field %s takes bits %s", names[i], l.bitSpan(f.Hi(), f.Lo))
+		switch {
+		case len(mc.mname) == 0:
+			if mc.shift != uint64(f.Lo) {
+				ds = append(ds, f.At(fmt.Sprintf("%s, %s is %d.", fb, mc.sname, mc.shift)))
+			}
+			continue
+		case mc.mask == 0:
+			ds = append(ds, f.At(fmt.Sprintf("%s, %s is 0.", fb, mc.mname)))
+			continue
+		}
+		m := mc.mask
+		if len(mc.sname) > 0 && m&1 == 1 && mc.shift > 0 && mc.shift < 64 {
+			m <<= mc.shift // unshifted mask
+		}
+		lo := bits.TrailingZeros64(m)
+		hi := 63 - bits.LeadingZeros64(m)
+		switch {
+		case bits.OnesCount64(m) != hi-lo+1:
+			ds = append(ds, f.At(fmt.Sprintf("%s, %s %#x has bits not in a row.", fb, mc.mname, mc.mask)))
+		case hi != f.Hi() || lo != f.Lo:
+			ds = append(ds, f.At(fmt.Sprintf("%s, %s has bits %s.", fb, mc.mname, l.bitSpan(hi, lo))))
+		case len(mc.sname) > 0 && mc.shift != uint64(lo):
+			ds = append(ds, f.At(fmt.Sprintf("%s, %s is %d.", fb, mc.sname, mc.shift)))
+		}
+	}
+	return
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import "testing"
+
+func TestMasks(t *testing.T) {
+	ps, _, err := scanFile(`testdata/masks.go`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{
+			`Field Seq takes bits 31..16, HdrSeqMask 0xf0f0 has bits not in a row.`,
+			`Field Len takes bits 47..32, HdrLenMask has bits 31..16.`,
+			`Field Id takes bits 58..48, HdrIdMask has bits 57..47.`,
+		},
+		{`Marker masks=Ctl* matches no Mask nor Shift constants.`},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("found %d picstrings, expected %d", len(ps), len(want))
+	}
+	for i, p := range ps {
+		_, ds := check(p.pic, p.mk.width, newMaskSet(p.pos.Filename, p.mk.masks), Numbering{})
+		if len(ds) != len(want[i]) {
+			t.Errorf("%s: got %v", p.tag, ds)
+			continue
+		}
+		for j, d := range ds {
+			if d.Rule != `BP009` || d.Msg != want[i][j] {
+				t.Errorf("%s: got %s [%s], expected %s", p.tag, d.Msg, d.Rule, want[i][j])
+			}
+		}
+	}
+}
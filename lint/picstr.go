@@ -0,0 +1,383 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+/*
+Package lint is the engine behind the bplint command.
+
+It splits Bitpeek picstrings into fields (Parse), runs checks over
+them (Rule, Check) and renders bit maps (Lint). Command bplint is
+a thin wrapper over Main, so a team may build its own linter binary
+with additional house rules:
+
+	package main
+
+	import "github.com/ohir/bplint/lint"
+
+	func main() {
+		lint.Register(lint.NewRule("HR001", ckUpperFlags))
+		lint.Main()
+	}
+*/
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kind tells what a picstring field shows.
+type Kind uint8
+
+// Field kinds.
+const (
+	KindFlag Kind = iota // <=>? flag with its label
+	KindBit              // B
+	KindHex              // H, [BEF]H+
+	KindOct              // EFF
+	KindNum              // lone E, F, G and their chains
+	KindChar             // A (7b), C (8b)
+	KindDec              // D.dd@
+	KindIPv4             // IPv4.Address32@
+	KindSkip             // !dd@
+)
+
+var kindNames = [...]string{`flag`, `bit`, `hex`, `oct`, `num`, `char`, `dec`, `ipv4`, `skip`}
+var kindIdents = [...]string{`Flag`, `Bit`, `Hex`, `Oct`, `Num`, `Char`, `Dec`, `Addr`, `Skip`}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return `?`
+}
+
+// Field is a single command of a picstring with the label text that
+// precedes it.
+type Field struct {
+	Kind  Kind
+	Label string // text between previous command and this one
+	Cmd   string // command characters, eg. FHH or D.16@
+	Pos   int    // offset of Cmd in the picstring
+	Lo    int    // lowest bit taken
+	Width int    // bits taken
+
+	prev, next byte // chars around Cmd, for shape checks
+}
+
+// Hi returns the highest bit taken by the field.
+func (f *Field) Hi() int { return f.Lo + f.Width - 1 }
+
+// Pic returns field's part of the picstring: Label followed by Cmd.
+func (f *Field) Pic() string { return f.Label + f.Cmd }
+
+// Layout is a parsed picstring.
+type Layout struct {
+	Pic    string  // picstring itself
+	Fields []Field // left to right, from the most significant bit
+	Tail   string  // text after the last command
+	Bits   int     // total bits taken
+	Width  int     // declared width, 64 unless marker tells otherwise
+	Num    Numbering
+
+	masks *maskSet // constants to check fields against, see BP009
+}
+
+// BitOrder is numbering of bits in labels. NativeBits is the own one
+// of a renderer: MSB0 for RFC diagrams, LSB0 for all else.
+type BitOrder int
+
+const (
+	NativeBits BitOrder = iota
+	LSB0                // bit 0 is the least significant one
+	MSB0                // bit 0 is the most significant one, as in network specs
+)
+
+// ByteOrder is numbering of bytes and nibbles in labels. NativeBytes
+// is little endian.
+type ByteOrder int
+
+const (
+	NativeBytes ByteOrder = iota
+	Little                // byte 0 is the least significant one
+	Big                   // byte 0 is the most significant one, first on the wire
+)
+
+// Numbering tells how bits and bytes of a layout are labeled in maps,
+// exports and diagnostics. Fields keep their Lo counted from the least
+// significant bit whatever the numbering.
+type Numbering struct {
+	Bits  BitOrder
+	Bytes ByteOrder
+}
+
+// bitNo returns label of bit b, counted from the least significant one.
+// With native numbering bits are MSB0 if msb0 is set.
+func (l *Layout) bitNo(b int, msb0 bool) int {
+	if l.Num.Bits == MSB0 || l.Num.Bits == NativeBits && msb0 {
+		return l.Width - 1 - b
+	}
+	return b
+}
+
+// bitSpan returns label of bits hi..lo, the most significant first.
+func (l *Layout) bitSpan(hi, lo int) string {
+	return fmt.Sprintf("%d..%d", l.bitNo(hi, false), l.bitNo(lo, false))
+}
+
+// unitNo returns label of the n bit unit, byte or nibble, bit b is in.
+func (l *Layout) unitNo(b, n int) int {
+	if l.Num.Bytes == Big {
+		return (l.Width+n-1)/n - 1 - b/n
+	}
+	return b / n
+}
+
+// Name returns the field name taken from its label: a flag's own label
+// or the word put right before the command, like Type in "Type:'F" or
+// Id in "Id:0xFHH". Quoted text makes a name too, eg. 'Type of Service':
+// gives TypeOfService. It is empty if label gives no plain ASCII name.
+func (f *Field) Name() string {
+	t := f.Label
+	if f.Kind != KindFlag {
+		t = strings.TrimSuffix(strings.TrimRight(t, `'`), `0x`)
+		if len(t) == 0 || strings.IndexByte(":=#", t[len(t)-1]) < 0 {
+			return ``
+		}
+		t = t[:len(t)-1]
+	}
+	if q, ok := quotedEnd(t); ok {
+		for i := 0; i < len(q); i++ {
+			if q[i] >= 0x80 {
+				return ``
+			}
+			if !isWordChar(q[i]) {
+				return ident(q)
+			}
+		}
+		t = q
+	}
+	i := len(t)
+	for i > 0 && isWordChar(t[i-1]) {
+		i--
+	}
+	if i < len(t) && t[i] >= '0' && t[i] <= '9' {
+		return ``
+	}
+	if i > 0 && t[i-1] >= 0x80 { // part of a non ASCII word
+		return ``
+	}
+	return t[i:]
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c|0x20 >= 'a' && c|0x20 <= 'z'
+}
+
+// quotedEnd returns text of the quote t ends with, if it does.
+func quotedEnd(t string) (string, bool) {
+	if !strings.HasSuffix(t, `'`) {
+		return ``, false
+	}
+	t = t[:len(t)-1]
+	i := strings.LastIndexByte(t, '\'')
+	if i < 0 {
+		return ``, false
+	}
+	return t[i+1:], true
+}
+
+// Show returns what a field is shown as: label of a flag without quotes,
+// dots and spaces, quoted name put before the command, or the name given.
+func (f *Field) Show(name string) string {
+	if f.Kind == KindFlag {
+		if s := strings.Trim(f.Label, ` '.`); len(s) > 0 {
+			return s
+		}
+	}
+	if q, ok := quotedEnd(strings.TrimRight(f.Label, `:=#`)); ok && len(q) > 0 {
+		return q
+	}
+	return name
+}
+
+// Names returns unique names of fields, made of labels (see Field.Name)
+// or kinds and bit positions if there is no label, eg. Dec0.
+func (l *Layout) Names() []string {
+	r := make([]string, len(l.Fields))
+	seen := map[string]int{}
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		n := f.Name()
+		if len(n) == 0 && int(f.Kind) < len(kindIdents) {
+			n = fmt.Sprintf("%s%d", kindIdents[f.Kind], f.Lo)
+		}
+		if seen[n]++; seen[n] > 1 {
+			n = fmt.Sprintf("%s_%d", n, seen[n])
+		}
+		r[i] = n
+	}
+	return r
+}
+
+// Parse splits picstring into fields. Errors returned are *Diagnostic
+// for malformed dd@ commands, the Layout then holds fields found to
+// the right of the error. Number shapes are not checked here, it is
+// the job of Rules run by Check.
+func Parse(inp string) (*Layout, error) {
+	pic := "?" + inp + " " // simplify for loop output
+	pi := len(pic) - 1     // pic index
+	var bi uint16          // bit index
+	var quoted, label bool // flow control
+	var fs []Field         // right to left
+	l := &Layout{Pic: inp, Width: 64}
+	defer func() { // put fields in reading order
+		for i, j := 0, len(fs)-1; i < j; i, j = i+1, j-1 {
+			fs[i], fs[j] = fs[j], fs[i]
+		}
+		l.Fields = fs
+		l.Bits = int(bi)
+	}()
+	for pi > 0 {
+		pi--
+		w := pic[pi]
+		switch {
+		case pi == 0:
+			w = '?'
+		case pi > 0 && pic[pi-1] == '\\':
+			pi--
+			continue
+		case w == '\'' && (label || quoted):
+			label = false
+			quoted = false
+			continue
+		case w == '\'':
+			quoted = true
+			continue
+		case quoted && w != '\'':
+			continue
+		case label && w|3 != 63:
+			continue
+		case w|3 == 63:
+			label = true
+		case w == 'D' || w < 0x3c || w > 0x48:
+			continue
+		}
+		if n := len(fs); n > 0 { // label of the field on the right
+			fs[n-1].Label = pic[pi+1 : fs[n-1].Pos+1]
+		} else {
+			l.Tail = pic[pi+1 : len(pic)-1]
+		}
+		if pi <= 0 {
+			break
+		}
+
+		var err error
+		k := KindBit
+		end := pi
+		lo := bi
+		switch {
+		case w|3 == 63: // single bits - bbChain
+			k = KindFlag
+			bi++
+		case w == 'B':
+			bi++
+		case w == '@': // varbits, Number
+			pi, bi, k, err = ckVarblen(pic, pi, bi)
+		default: // ACEFGH - bbRange
+			pi, bi, k = ckRanges(pic, pi, bi)
+		}
+		if err != nil {
+			if pi--; pi < 0 {
+				pi = 0
+			}
+			return l, &Diagnostic{Rule: `BP000`, Pos: pi, End: end, Msg: err.Error()}
+		}
+		fs = append(fs, Field{Kind: k, Cmd: pic[pi : end+1], Pos: pi - 1,
+			Lo: int(lo), Width: int(bi - lo), prev: pic[pi-1], next: pic[end+1]})
+	}
+	return l, nil
+}
+
+func ckVarblen(pic string, pi int, bi uint16) (int, uint16, Kind, error) {
+	if pi < 3 { // !dd@
+		return 0, bi, KindSkip, errors.New("Misplaced @")
+	}
+	k := (10 * uint8(pic[pi-2]-48)) + uint8(pic[pi-1]-48)
+	var d = 4
+	if k > 16 {
+		d = int(k / 3)
+	}
+	switch {
+	case k == 0, k > 64:
+		return pi - 2, bi, KindSkip, errors.New("Bad bitcount.")
+	case pi > 2 && pic[pi-3] == '!': // !dd@ skip dd bits
+		return pi - 3, bi + uint16(k), KindSkip, nil
+	case pi > d-1 && pic[pi-d] == 'D': // D.dd@ Decimal
+		return pi - d, bi + uint16(k), KindDec, nil
+	case pi > 13 && pic[pi-14] == 'I': // I##.###.###.32@ is not now allowed
+		pi -= 14
+		bi += 32
+		if pic[pi:pi+15] != `IPv4.Address32@` { // force it
+			return pi, bi, KindIPv4, errors.New("Invalid pic for IPv4.")
+		}
+		return pi, bi, KindIPv4, nil
+	}
+	return pi - 2, bi, KindSkip, errors.New("Can't find valid start command for this dd@.")
+}
+
+// ckRanges takes whole number commands. Their shapes are checked later
+// by rules, see section 'Valid Numbers' in docs.
+//
+// before series of H can come a *single* completing digit of F, E or B
+// before series of F can come a *single* completing digit of E (octal)
+func ckRanges(pic string, pi int, bi uint16) (int, uint16, Kind) {
+	switch pic[pi] {
+	case 'H': // HHH FHH EHH BHH
+	nextH:
+		bi += 4
+		if pic[pi-1] == 'H' {
+			pi--
+			goto nextH
+		}
+		switch pic[pi-1] { // only single B|E|F allowed after H's
+		case 'F':
+			bi += 3
+			pi--
+		case 'E':
+			bi += 2
+			pi--
+		case 'B':
+			bi += 1
+			pi--
+		}
+		return pi, bi, KindHex
+	case 'C':
+		return pi, bi + 8, KindChar
+	case 'A':
+		return pi, bi + 7, KindChar
+	case 'G':
+		return pi, bi + 5, KindNum
+	case 'F': // FFF EFF BFF
+		efs := 1
+	nextF:
+		bi += 3
+		if pic[pi-1] == 'F' {
+			efs++
+			pi--
+			goto nextF
+		}
+		if efs == 2 && pic[pi-1] == 'E' {
+			return pi - 1, bi + 2, KindOct // valid octal shape
+		}
+	case 'E': // separate entity
+	nextE:
+		bi += 2
+		if pic[pi-1] == 'E' {
+			pi--
+			goto nextE
+		}
+	}
+	return pi, bi, KindNum
+}
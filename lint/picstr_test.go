@@ -0,0 +1,32 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNumbering(t *testing.T) {
+	l, ds := check(lintTests[0].pic, 64, nil, Numbering{MSB0, Big})
+	want := []string{
+		`byte:|0         |0     |0    |0..1~~~~~~~~|2..5                 |6..7     |`,
+		`bits:|0.. 3b ..2|     3|    4|5.. 11b ..15|16..     32b     ..47|48 16b 63|`,
+		`               ^      ^     ^            ^                     ^         ^|`,
+		`cmds:¨¨¨¨Type:'F¨ 'EXT=¨.ACK=¨¨¨¨ Id:0xFHH¨ from IPv4.Address32@¨¨¨¨:D.16@¨`}
+	_, segs := lintSegs(l, ds, utf8R, 0, 1)
+	for k := range want {
+		if segs[0][k] != want[k] {
+			t.Errorf("row %d:\n%q\nwant\n%q", k, segs[0][k], want[k])
+		}
+	}
+	if r := tableRows(l); r[0][2] != `0..2` || r[5][2] != `48..63` {
+		t.Errorf("table bits: %q, %q", r[0][2], r[5][2])
+	}
+	l, _ = check(`'A= !03@ 'Length':HH`, 16, nil, Numbering{LSB0, Little})
+	if r := RFCDiagram(l); !strings.HasPrefix(r, "           1                   0\n 5 4 3 2 1 0 9 8 7 6 5 4 3 2 1 0\n") {
+		t.Errorf("LSB0 RFC ruler:\n%s", r)
+	}
+}
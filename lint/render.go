@@ -0,0 +1,213 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lint checks picstring and renders its map to the input bits. The o[0]
+// is either "OK." or "Error: " with the first diagnostic.
+func Lint(pic string) (o [4]string) {
+	l, ds := Check(pic)
+	return lintMap(l, ds, utf8R)
+}
+
+// lintMap renders map of the layout with the first of ds, if any.
+func lintMap(l *Layout, ds []Diagnostic, r renderer) (o [4]string) {
+	e0, segs := lintSegs(l, ds, r, 0, 0)
+	return [4]string{e0, segs[0][0], segs[0][1], segs[0][2]}
+}
+
+// lintSegs renders map of the layout like lintMap, but split at field
+// boundaries into segments of rows, each fitting width columns if it
+// is above 0. Every segment starts with the high bit of its first field
+// and ends with the low bit of its last one. Rows of a segment are
+// rulers, if asked for (1 byte, 2 byte and nibble), then bits, marks
+// and cmds.
+func lintSegs(l *Layout, ds []Diagnostic, r renderer, width, rulers int) (e0 string, segs [][]string) {
+	// byte:|    3     |3..2~~~~~~~~~~~~~~~~|   2 |   2    |
+	// bits:|  b28..b27 | b26..  4b ..b24 | b23 | b22..b20 |
+	//                ^                 ^     ^          ^
+	// cmds:¨       Ac:E           Press:H  'CS= ````Stat:F
+
+	var d *Diagnostic
+	e0 = `OK.`
+	if len(ds) > 0 {
+		d = &ds[0]
+		e0 = r.paint(r.text(fmt.Sprintf("Error: %s", d)), -1)
+	}
+	rp := render(l, d, r)
+	if len(rp) < 3 { // picstring shown at the error
+		rulers = 0
+	}
+	o := make([]strings.Builder, rulers+3)
+	cw, n := 0, 0 // columns and fields of the segment
+	add := func(p part) {
+		for k := 0; k < rulers; k++ {
+			o[k].WriteString(p.rule[k])
+		}
+		o[rulers].WriteString(p.bits)
+		o[rulers+1].WriteString(p.mark)
+		o[rulers+2].WriteString(p.pics)
+		cw += max(r.width(p.bits), r.width(p.pics))
+	}
+	flush := func() {
+		seg := make([]string, len(o))
+		for k := range o {
+			seg[k] = o[k].String()
+			o[k].Reset()
+		}
+		segs = append(segs, seg)
+		cw, n = 0, 0
+	}
+	if len(rp) < 3 {
+		add(rp[0])
+		flush()
+		return
+	}
+	add(rp[0])
+	for _, p := range rp[1 : len(rp)-2] {
+		if width > 0 && n > 0 && cw+max(r.width(p.bits), r.width(p.pics))+1 > width {
+			for k := 0; k < rulers+2; k++ {
+				o[k].WriteString(`|`)
+			}
+			flush()
+			add(rp[0])
+		}
+		add(p)
+		n++
+	}
+	add(rp[len(rp)-2])
+	add(rp[len(rp)-1])
+	flush()
+	return
+}
+
+type part struct {
+	bits string
+	mark string
+	pics string
+	rule [2]string // byte and nibble ruler cells
+}
+
+// render builds map parts of the layout drawn by r. If d points at
+// a part of the picstring, it is shown instead of the map.
+func render(l *Layout, d *Diagnostic, r renderer) []part {
+	pic := "?" + l.Pic + " "
+	if d != nil && d.End > 0 {
+		var sp strings.Builder
+		for i := range pic[:d.End] {
+			if i >= d.Pos-1 { // -1: show at least boundary
+				sp.WriteByte('^')
+			} else {
+				sp.WriteByte(' ')
+			}
+		}
+		return []part{{pics: r.paint(sp.String()+`HERE`, -1), mark: r.text(pic[1:])}}
+	}
+	rp := make([]part, 0, len(l.Fields)+3)
+	rp = append(rp, part{`bits:`, `     `, `cmds:` + string(r.fill()), [2]string{`byte:`, `nibl:`}})
+	if d != nil {
+		rp[0].bits = r.paint(` ERR:`, -1)
+	}
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		// width of the field pic as seen from the previous command
+		lenC := r.width(r.text(pic[f.Pos-len(f.Label) : f.Pos+len(f.Cmd)]))
+		lenC += 1 // add for separator
+		p := mkPart(f, l.bitNo(f.Hi(), false), l.bitNo(f.Lo, false), lenC, r)
+		p.rule = [2]string{ruleCell(l, f, 8, len(p.bits), r), ruleCell(l, f, 4, len(p.bits), r)}
+		p.bits, p.mark, p.pics = r.paint(p.bits, i), r.paint(p.mark, i), r.paint(p.pics, i)
+		rp = append(rp, p)
+	}
+	rp = append(rp, part{pics: r.text(l.Tail)})
+	return append(rp, part{bits: `|`, mark: `|`, rule: [2]string{`|`, `|`}})
+}
+
+// ruleCell returns ruler cell of the field, w columns wide, telling
+// which n bit units (bytes, nibbles) it is in. Fields that straddle a
+// unit boundary, not starting or ending at one, are highlighted: their
+// cell is filled with ~ and painted as errors.
+func ruleCell(l *Layout, f *Field, n, w int, r renderer) string {
+	if f.Width == 0 {
+		return ``
+	}
+	hi, lo := l.unitNo(f.Hi(), n), l.unitNo(f.Lo, n)
+	s := fmt.Sprintf("|%d", hi)
+	if hi != lo {
+		s = fmt.Sprintf("|%d..%d", hi, lo)
+	}
+	if hi == lo || f.Lo%n == 0 && (f.Hi()+1)%n == 0 {
+		return s + strings.Repeat(` `, max(0, w-len(s)))
+	}
+	return `|` + r.paint(s[1:]+strings.Repeat(`~`, max(0, w-len(s))), -1)
+}
+
+// mkPart makes map part of the field with its bits labeled hi and lo.
+func mkPart(f *Field, hi, lo, lenC int, r renderer) part {
+	var b strings.Builder // |  b28..b27 | b26..  4b ..b24 | b23 | b22..b20 |
+	var m strings.Builder //           ^                 ^     ^          ^
+	var s strings.Builder //        Ac:E           Press:H  'CS= ````Stat:F
+
+	lenB := f.Width
+	curbitstart := lo
+	// bitdesc
+	var bDesc string
+	if lenB == 1 {
+		fmt.Fprintf(&b, "|%d", curbitstart) // single bit
+		bDesc = b.String()
+		if lenC > len(bDesc) { // adjust
+			adj := lenC - len(bDesc)
+			b.Reset()
+			fmt.Fprintf(&b, "|")
+			for i := adj; i > 0; i-- {
+				b.WriteByte(' ') // adjust left
+			}
+			fmt.Fprintf(&b, "%d", curbitstart)
+			bDesc = b.String()
+		}
+	}
+	if lenB > 1 {
+		b.Reset()
+		fmt.Fprintf(&b, "|%d %db %d", hi, lenB, curbitstart)
+		bdMid := b.String()
+		b.Reset()
+		fmt.Fprintf(&b, "|%d.. %db ..%d", hi, lenB, curbitstart)
+		bdLong := b.String()
+		b.Reset()
+		switch {
+		case lenC <= len(bdMid): // short
+			bDesc = bdMid
+		case lenC <= len(bdLong): // long
+			bDesc = bdLong
+		default: // adjust desc to fit pic
+			adj := lenC - len(bdLong)
+			fmt.Fprintf(&b, "|%d.. ", hi)
+			for i := adj - adj/2; i > 0; i-- {
+				b.WriteByte(' ') // adjust left
+			}
+			fmt.Fprintf(&b, "%db", lenB)
+			for i := adj / 2; i > 0; i-- {
+				b.WriteByte(' ') // adjust right
+			}
+			fmt.Fprintf(&b, " ..%d", curbitstart)
+			bDesc = b.String()
+		}
+	}
+	for i := lenC; i < len(bDesc); i++ {
+		s.WriteRune(r.fill()) // mark our inserts, diaresis in utf8
+	}
+	fmt.Fprintf(&s, "%s%c", r.text(f.Pic()), r.fill())
+	if lenB > 0 { // make marker
+		m.WriteByte(' ')
+		for i := len(bDesc) - 2; i > 0; i-- {
+			m.WriteByte(' ')
+		}
+		m.WriteByte('^')
+	}
+	return part{bits: bDesc, mark: m.String(), pics: s.String()}
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+)
+
+const htmlHead = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Bit layouts</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre, code { font-family: monospace; }
+section { margin: 2em 0; }
+.err { color: #b00; }
+.legend span { display: inline-block; padding: 0 .5em; margin-right: .3em; border: 1px solid #333; }
+</style></head><body>
+<h1>Bit layouts</h1>
+`
+
+// mapHTML makes a report of picstrings: an index with links to their
+// sections and source files, then a diagram of each. Findings are
+// listed for picstrings that do not check clean.
+func mapHTML(es []mapEntry) string {
+	var b strings.Builder
+	b.WriteString(htmlHead)
+	b.WriteString(`<p class="legend">`)
+	for _, k := range []Kind{KindFlag, KindHex, KindOct, KindDec, KindIPv4, KindSkip} {
+		fmt.Fprintf(&b, `<span style="background:%s">%s</span>`, svgColors[k], k)
+	}
+	b.WriteString("</p>\n<ul>\n")
+	src := func(e *mapEntry) string {
+		fn := filepath.ToSlash(e.pos.Filename)
+		return fmt.Sprintf(`<a href="%s#L%d">%s line %d</a>`, html.EscapeString(fn), e.pos.Line, html.EscapeString(fn), e.pos.Line)
+	}
+	for i := range es {
+		e := &es[i]
+		bad := ``
+		if len(e.ds) > 0 {
+			bad = ` <span class="err">(errors)</span>`
+		}
+		fmt.Fprintf(&b, "<li><a href=\"#pic%d\">%s</a>%s in %s</li>\n", i+1, html.EscapeString(e.tag), bad, src(e))
+	}
+	b.WriteString("</ul>\n")
+	for i := range es {
+		e := &es[i]
+		fmt.Fprintf(&b, "<section id=\"pic%d\">\n<h2>%s</h2>\n<p>%s</p>\n<pre>%s</pre>\n",
+			i+1, html.EscapeString(e.tag), src(e), html.EscapeString(e.pic))
+		if len(e.ds) > 0 {
+			for _, d := range e.ds {
+				fmt.Fprintf(&b, "<p class=\"err\">Error: %s [%s]</p>\n", html.EscapeString(d.Msg), d.Rule)
+			}
+		} else {
+			b.WriteString(SVGDiagram(e.l))
+		}
+		b.WriteString("</section>\n")
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
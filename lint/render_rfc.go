@@ -0,0 +1,134 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	rwid "github.com/mattn/go-runewidth"
+)
+
+// box is a part of a field drawn in a single row of a diagram.
+type box struct {
+	name string
+	w    int  // bits
+	hi   int  // highest bit number
+	kind Kind // of the field
+	pad  bool // bits above the last field
+}
+
+// rowBoxes splits layout into rows of n bits, most significant bit
+// first. Bits above the last field, up to the declared width, make
+// an Unused box; skips are Reserved. Fields that cross a row are drawn
+// in both, named alike.
+func rowBoxes(l *Layout, n int) (rows [][]box) {
+	var bs []box
+	if pad := l.Width - l.Bits; pad > 0 {
+		bs = append(bs, box{`Unused`, pad, l.Width - 1, KindSkip, true})
+	}
+	names := l.Names()
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		name := f.Show(names[i])
+		if f.Kind == KindSkip {
+			name = `Reserved`
+		}
+		bs = append(bs, box{name, f.Width, f.Hi(), f.Kind, false})
+	}
+	var row []box
+	left := n
+	for _, b := range bs {
+		for b.w > 0 {
+			w := min(b.w, left)
+			row = append(row, box{b.name, w, b.hi, b.kind, b.pad})
+			b.w -= w
+			b.hi -= w
+			if left -= w; left == 0 {
+				rows, row, left = append(rows, row), nil, n
+			}
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return
+}
+
+// footKeys mark names put into footnotes, single chars to fit one bit.
+const footKeys = `123456789abcdefghijklmnopqrstuvwxyz`
+
+// RFCDiagram renders layout as an RFC style packet diagram: a 0 1 2 3
+// ruler over +-+-+ boxes, 32 bits per row, most significant bit first
+// and numbered 0. If l.Num tells LSB0 every row gets its own ruler
+// instead, numbered down from its most significant bit, eg. 63..32 and
+// 31..0 of a 64 bit layout. Field names are centered in their boxes,
+// names too long for a box are put in footnotes below. Names are
+// measured in terminal cells, not bytes.
+func RFCDiagram(l *Layout) string {
+	var b strings.Builder
+	n := min(32, l.Width)
+	ruler := func(bits int, no func(i int) int) {
+		var tens, ones strings.Builder
+		for i := 0; i < bits; i++ {
+			k := no(i)
+			d := ` `
+			if k%10 == 0 {
+				d = fmt.Sprint(k / 10)
+			}
+			fmt.Fprintf(&tens, " %s", d)
+			fmt.Fprintf(&ones, " %d", k%10)
+		}
+		fmt.Fprintf(&b, "%s\n%s\n", strings.TrimRight(tens.String(), ` `), ones.String())
+	}
+	lsb0 := l.bitNo(0, true) == 0
+	if !lsb0 {
+		ruler(n, func(i int) int { return i }) // rows are numbered alike
+	}
+	var notes []string
+	border := func(bits int) { b.WriteString(strings.Repeat(`+-`, bits) + "+\n") }
+	bits := 0
+	for r, row := range rowBoxes(l, 32) {
+		w := 0
+		for _, x := range row {
+			w += x.w
+		}
+		if lsb0 {
+			if r > 0 {
+				border(n) // close the row above, its ruler is over it
+			}
+			top := l.Width - 1 - 32*r
+			ruler(w, func(i int) int { return l.bitNo(top-i, true) })
+			border(w) // under its own ruler a short row gets a short top
+		} else {
+			border(n) // all rows but the last are full
+		}
+		bits = w
+		for _, x := range row {
+			in := 2*x.w - 1
+			s := x.name
+			if rwid.StringWidth(s) > in {
+				key := `?`
+				if k := len(notes); k < len(footKeys) {
+					key = footKeys[k : k+1]
+				}
+				notes = append(notes, fmt.Sprintf("%s: %s", key, s))
+				s = key
+			}
+			sw := rwid.StringWidth(s)
+			lp := (in - sw) / 2
+			fmt.Fprintf(&b, "|%s%s%s", strings.Repeat(` `, lp), s, strings.Repeat(` `, in-sw-lp))
+		}
+		b.WriteString("|\n")
+	}
+	border(bits)
+	for _, v := range notes {
+		fmt.Fprintf(&b, "\n  %s", v)
+	}
+	if len(notes) > 0 {
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
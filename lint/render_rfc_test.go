@@ -0,0 +1,101 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import "testing"
+
+var rfcTests = []struct {
+	pic   string
+	width int
+	out   string
+}{
+	{`Type:'F 'EXT=.ACK= Id:0xFHH from IPv4.Address32@:D.16@`, 64, `
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|Type |1|2|         Id          |            Addr16             |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|            Addr16             |             Dec0              |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+  1: EXT
+  2: ACK
+`},
+	{`'A= !03@ 'Len':HH`, 40, `
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                        Unused                         |A|  1  |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|      Len      |
++-+-+-+-+-+-+-+-+
+
+  1: Reserved
+`},
+	{`'Ąę':H 'Źż':H`, 8, `
+ 0
+ 0 1 2 3 4 5 6 7
++-+-+-+-+-+-+-+-+
+|  Ąę   |  Źż   |
++-+-+-+-+-+-+-+-+
+`},
+	{`'Version':H 'IHL':H`, 8, `
+ 0
+ 0 1 2 3 4 5 6 7
++-+-+-+-+-+-+-+-+
+|Version|  IHL  |
++-+-+-+-+-+-+-+-+
+`},
+}
+
+// LSB0 rows are numbered from their own most significant bit.
+func TestRFCDiagramLSB0(t *testing.T) {
+	l, _ := check(`'A= !03@ 'Length':HH`, 40, nil, Numbering{LSB0, Little})
+	want := `
+                   3                   2                   1
+ 9 8 7 6 5 4 3 2 1 0 9 8 7 6 5 4 3 2 1 0 9 8 7 6 5 4 3 2 1 0 9 8
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                        Unused                         |A|  1  |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+               0
+ 7 6 5 4 3 2 1 0
++-+-+-+-+-+-+-+-+
+|    Length     |
++-+-+-+-+-+-+-+-+
+
+  1: Reserved
+`
+	if r := RFCDiagram(l); r != want[1:] {
+		t.Errorf("got:\n%s\nexpected:\n%s", r, want[1:])
+	}
+	l, _ = check(lintTests[0].pic, 64, nil, Numbering{LSB0, Little})
+	want = `
+       6                   5                   4
+ 3 2 1 0 9 8 7 6 5 4 3 2 1 0 9 8 7 6 5 4 3 2 1 0 9 8 7 6 5 4 3 2
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|Type |1|2|         Id          |            Addr16             |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+   3                   2                   1                   0
+ 1 0 9 8 7 6 5 4 3 2 1 0 9 8 7 6 5 4 3 2 1 0 9 8 7 6 5 4 3 2 1 0
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|            Addr16             |             Dec0              |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+  1: EXT
+  2: ACK
+`
+	if r := RFCDiagram(l); r != want[1:] {
+		t.Errorf("64 bits got:\n%s\nexpected:\n%s", r, want[1:])
+	}
+}
+
+func TestRFCDiagram(t *testing.T) {
+	for _, v := range rfcTests {
+		l, _ := check(v.pic, v.width, nil, Numbering{})
+		if r := RFCDiagram(l); r != v.out[1:] {
+			t.Errorf("%q: got:\n%s\nexpected:\n%s", v.pic, r, v.out[1:])
+		}
+	}
+}
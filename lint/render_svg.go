@@ -0,0 +1,73 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// svgColors fill boxes by kind of the field.
+var svgColors = [...]string{
+	KindFlag: `#ffe08a`, KindBit: `#a8d5ff`, KindHex: `#a8d5ff`,
+	KindOct: `#cbb8ff`, KindNum: `#a8d5ff`, KindChar: `#a8d5ff`,
+	KindDec: `#b8f2c2`, KindIPv4: `#ffc2a8`, KindSkip: `#e0e0e0`,
+}
+
+// svg diagram geometry, px
+const (
+	svgBit  = 22 // bit cell width
+	svgBox  = 40 // box height
+	svgIdx  = 14 // bit index line height
+	svgPad  = 8  // margin
+	svgChar = 7  // approximate width of a name char
+)
+
+// SVGDiagram renders layout as an SVG register diagram, 32 bits per row,
+// most significant bit first. Boxes are as wide as fields, numbered with
+// bits at their edges, LSB0 unless l.Num tells otherwise, and colored
+// by kind. Names too long for a box are cut, the full one shows as
+// a tooltip.
+func SVGDiagram(l *Layout) string {
+	rows := rowBoxes(l, 32)
+	n := min(32, l.Width)
+	rh := svgIdx + svgBox + svgPad
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="12">`+"\n",
+		2*svgPad+n*svgBit, svgPad+len(rows)*rh)
+	for r, row := range rows {
+		x, y := svgPad, svgPad+r*rh
+		for _, bx := range row {
+			w := bx.w * svgBit
+			cls, fill := `k-`+bx.kind.String(), svgColors[bx.kind]
+			if bx.pad {
+				cls, fill = `k-unused`, `#ffffff`
+			}
+			fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="10">%d</text>`+"\n", x+2, y+svgIdx-3, l.bitNo(bx.hi, false))
+			if lo := bx.hi - bx.w + 1; bx.w > 1 {
+				fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="10" text-anchor="end">%d</text>`+"\n", x+w-2, y+svgIdx-3, l.bitNo(lo, false))
+			}
+			name := []rune(bx.name)
+			cx, cy := x+w/2, y+svgIdx+svgBox/2+4
+			rot := `` // names of narrow boxes go upright
+			fit := (w - 4) / svgChar
+			if len(name) > fit && bx.w <= 2 {
+				rot = fmt.Sprintf(` transform="rotate(-90 %d %d)"`, cx, cy-4)
+				fit = (svgBox - 4) / svgChar
+			}
+			if len(name) > fit {
+				name = append(name[:max(fit-1, 0)], '…')
+			}
+			fmt.Fprintf(&b, `<g class="%s"><title>%s</title><rect x="%d" y="%d" width="%d" height="%d" fill="%s" stroke="#333"/>`,
+				cls, html.EscapeString(bx.name), x, y+svgIdx, w, svgBox, fill)
+			fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="middle"%s>%s</text></g>`+"\n",
+				cx, cy, rot, html.EscapeString(string(name)))
+			x += w
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSVGDiagram(t *testing.T) {
+	l, _ := check(`'A= !03@ 'Length':HH`, 40, nil, Numbering{})
+	r := SVGDiagram(l)
+	for _, v := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" width="720" height="132"`,
+		`<g class="k-unused"><title>Unused</title><rect x="8" y="22" width="616"`,
+		`<g class="k-flag"><title>A</title>`,
+		`<g class="k-skip"><title>Reserved</title><rect x="646" y="22" width="66" height="40" fill="#e0e0e0"`,
+		`<g class="k-hex"><title>Length</title><rect x="8" y="84" width="176"`,
+		`<text x="10" y="81" font-size="10">7</text>`,
+		`<text x="182" y="81" font-size="10" text-anchor="end">0</text>`,
+	} {
+		if !strings.Contains(r, v) {
+			t.Errorf("svg lacks %s:\n%s", v, r)
+		}
+	}
+	if n := strings.Count(r, `<rect `); n != 4 {
+		t.Errorf("svg has %d boxes, expected 4", n)
+	}
+}
+
+func TestMapHTML(t *testing.T) {
+	files, errcnt, match = 0, 0, ``
+	es := mapFile(`testdata/gen.go`)
+	es = append(es, mapFile(`lint_test.go`)...)
+	r := mapHTML(es)
+	for _, v := range []string{
+		`<li><a href="#pic1">status</a> in <a href="testdata/gen.go#L4">testdata/gen.go line 4</a></li>`,
+		`<li><a href="#pic3">Ovl</a> <span class="err">(errors)</span> in`,
+		`<section id="pic2">` + "\n<h2>Example</h2>",
+		`<p class="err">Error: Pic string takes more than 64 bits! [BP001]</p>`,
+	} {
+		if !strings.Contains(r, v) {
+			t.Errorf("html lacks %s", v)
+		}
+	}
+	if errcnt == 0 {
+		t.Errorf("errors not counted")
+	}
+	files, errcnt = 0, 0
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+var tableHead = []string{`Name`, `Kind`, `Bits`, `Width`, `Mask`, `Shift`, `Picture`}
+
+// tableRows returns a row of tableHead columns for every field. Masks
+// have as many hex digits as the declared width needs.
+func tableRows(l *Layout) (rs [][]string) {
+	names := l.Names()
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		m := (uint64(1)<<uint(f.Width) - 1) << uint(f.Lo)
+		rs = append(rs, []string{f.Show(names[i]), f.Kind.String(),
+			l.bitSpan(f.Hi(), f.Lo), fmt.Sprint(f.Width),
+			fmt.Sprintf("0x%0*x", (l.Width+3)/4, m), fmt.Sprint(f.Lo),
+			strings.TrimSpace(f.Pic())})
+	}
+	return
+}
+
+// MarkdownTable renders fields of the layout as a GitHub Markdown table.
+func MarkdownTable(l *Layout) string {
+	var b strings.Builder
+	row := func(cs []string) {
+		for _, c := range cs {
+			fmt.Fprintf(&b, "| %s ", strings.Replace(c, `|`, `\|`, -1))
+		}
+		b.WriteString("|\n")
+	}
+	row(tableHead)
+	b.WriteString(strings.Repeat(`|---`, len(tableHead)) + "|\n")
+	for _, r := range tableRows(l) {
+		r[6] = "`` " + r[6] + " ``"
+		row(r)
+	}
+	return b.String()
+}
+
+// CSVTable renders fields of the layout as CSV with a header line.
+func CSVTable(l *Layout) string {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(tableHead)
+	w.WriteAll(tableRows(l))
+	return b.String()
+}
+
+// AsciiDocTable renders fields of the layout as an AsciiDoc table.
+func AsciiDocTable(l *Layout) string {
+	var b strings.Builder
+	b.WriteString("[options=\"header\"]\n|===\n")
+	row := func(cs []string) {
+		for i, c := range cs {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprintf(&b, "|%s", strings.Replace(c, `|`, `\|`, -1))
+		}
+		b.WriteByte('\n')
+	}
+	row(tableHead)
+	for _, r := range tableRows(l) {
+		r[6] = "`+" + r[6] + "+`"
+		row(r)
+	}
+	b.WriteString("|===\n")
+	return b.String()
+}
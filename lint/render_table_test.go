@@ -0,0 +1,46 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import "testing"
+
+func TestTables(t *testing.T) {
+	l, _ := check(`Mode:EH 'ACK= !03@ 'Len|x':HH`, 24, nil, Numbering{})
+	for _, v := range []struct {
+		name string
+		mf   func(*Layout) string
+		out  string
+	}{
+		{`md`, MarkdownTable, `
+| Name | Kind | Bits | Width | Mask | Shift | Picture |
+|---|---|---|---|---|---|---|
+| Mode | hex | 17..12 | 6 | 0x03f000 | 12 | ` + "`` Mode:EH ``" + ` |
+| ACK | flag | 11..11 | 1 | 0x000800 | 11 | ` + "`` 'ACK= ``" + ` |
+| Skip8 | skip | 10..8 | 3 | 0x000700 | 8 | ` + "`` !03@ ``" + ` |
+| Len\|x | hex | 7..0 | 8 | 0x0000ff | 0 | ` + "`` 'Len\\|x':HH ``" + ` |
+`},
+		{`csv`, CSVTable, `
+Name,Kind,Bits,Width,Mask,Shift,Picture
+Mode,hex,17..12,6,0x03f000,12,Mode:EH
+ACK,flag,11..11,1,0x000800,11,'ACK=
+Skip8,skip,10..8,3,0x000700,8,!03@
+Len|x,hex,7..0,8,0x0000ff,0,'Len|x':HH
+`},
+		{`adoc`, AsciiDocTable, `
+[options="header"]
+|===
+|Name |Kind |Bits |Width |Mask |Shift |Picture
+|Mode |hex |17..12 |6 |0x03f000 |12 |` + "`+Mode:EH+`" + `
+|ACK |flag |11..11 |1 |0x000800 |11 |` + "`+'ACK=+`" + `
+|Skip8 |skip |10..8 |3 |0x000700 |8 |` + "`+!03@+`" + `
+|Len\|x |hex |7..0 |8 |0x0000ff |0 |` + "`+'Len\\|x':HH+`" + `
+|===
+`},
+	} {
+		if r := v.mf(l); r != v.out[1:] {
+			t.Errorf("%s: got:\n%s\nexpected:\n%s", v.name, r, v.out[1:])
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	rwid "github.com/mattn/go-runewidth"
+)
+
+// renderer draws the bits: and cmds: map for a kind of terminal.
+type renderer interface {
+	text(s string) string         // s as it can be shown
+	width(s string) int           // columns s takes when shown
+	fill() rune                   // marks our inserts to the cmds row
+	paint(s string, n int) string // s of the n-th field, errors at -1
+}
+
+// utf8Renderer is the plain UTF-8 map, with ¨ marking inserts.
+type utf8Renderer struct{}
+
+func (utf8Renderer) text(s string) string         { return s }
+func (utf8Renderer) width(s string) int           { return rwid.StringWidth(s) }
+func (utf8Renderer) fill() rune                   { return '¨' }
+func (utf8Renderer) paint(s string, n int) string { return s }
+
+// asciiRenderer is for terminals that can not show UTF-8: inserts are
+// marked with ` and non ascii characters of labels are shown as ?.
+type asciiRenderer struct{}
+
+func (asciiRenderer) text(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 0x7e || r < ' ' {
+			return '?'
+		}
+		return r
+	}, s)
+}
+func (asciiRenderer) width(s string) int           { return len(s) }
+func (asciiRenderer) fill() rune                   { return '`' }
+func (asciiRenderer) paint(s string, n int) string { return s }
+
+// ansiRenderer is the UTF-8 map colored with ANSI escapes: adjacent
+// fields alternate colors, errors are bold red.
+type ansiRenderer struct{ utf8Renderer }
+
+var ansiEsc = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func (ansiRenderer) width(s string) int { return rwid.StringWidth(ansiEsc.ReplaceAllString(s, ``)) }
+func (ansiRenderer) paint(s string, n int) string {
+	if len(strings.TrimSpace(s)) == 0 {
+		return s
+	}
+	c := [...]string{"\x1b[36m", "\x1b[33m"}[n&1] // cyan, yellow
+	if n < 0 {
+		c = "\x1b[1;31m"
+	}
+	return c + s + "\x1b[0m"
+}
+
+var (
+	utf8R  renderer = utf8Renderer{}
+	asciiR renderer = asciiRenderer{}
+	ansiR  renderer = ansiRenderer{}
+)
+
+// renderers by the name given with --render.
+var renderers = map[string]renderer{`utf8`: utf8R, `ascii`: asciiR, `ansi`: ansiR}
+
+// rnd draws maps printed to the terminal, set by --render or autoRenderer.
+var rnd = utf8R
+
+// autoRenderer picks renderer for the terminal: ascii on a dumb or
+// unknown one, ansi if stdout is a terminal and NO_COLOR is not set,
+// utf8 otherwise.
+func autoRenderer() renderer {
+	term := os.Getenv(`TERM`)
+	switch {
+	case term == `` || term == `dumb`:
+		return asciiR
+	case len(os.Getenv(`NO_COLOR`)) > 0:
+		return utf8R
+	}
+	if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		return ansiR
+	}
+	return utf8R
+}
+
+// setRender sets rnd to the renderer named with --render NAME.
+func setRender(name string) {
+	if rnd = renderers[name]; rnd == nil {
+		var ns []string
+		for k := range renderers {
+			ns = append(ns, k)
+		}
+		sort.Strings(ns)
+		prErr("Error: unknown --render "+name+", use one of: "+strings.Join(ns, `, `), false)
+		os.Exit(1)
+	}
+}
+
+// wrapAt is the width maps printed are wrapped to, set by -width. It is
+// 0 for no wrapping, -1 for the terminal width.
+var wrapAt = -1
+
+// termWidth returns wrapAt, or columns of the terminal if not set, or
+// of COLUMNS if stdout is not a terminal.
+func termWidth() int {
+	if wrapAt >= 0 {
+		return wrapAt
+	}
+	if n := ttyColumns(); n > 0 {
+		return n
+	}
+	n, _ := strconv.Atoi(os.Getenv(`COLUMNS`))
+	return n
+}
+
+// setWidth sets wrapAt to the number given with -width N.
+func setWidth(v string) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		prErr("Error: bad -width "+v+", need columns or 0 for no wrapping", false)
+		os.Exit(1)
+	}
+	wrapAt = n
+}
+
+// rulers are ruler rows put above maps printed, set by -ruler: 1 for
+// bytes, 2 for bytes and nibbles.
+var rulers int
+
+// setRuler sets rulers from -ruler byte or -ruler nibble.
+func setRuler(v string) {
+	switch v {
+	case `byte`:
+		rulers = 1
+	case `nibble`:
+		rulers = 2
+	default:
+		prErr("Error: bad -ruler "+v+", use byte or nibble", false)
+		os.Exit(1)
+	}
+}
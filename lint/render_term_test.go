@@ -0,0 +1,53 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderers(t *testing.T) {
+	pic := `Tśćę:'F 'EXT=.ACK= Id:0xFHH`
+	l, ds := Check(pic)
+	want := [4]string{
+		`OK.`,
+		`bits:|15 3b 13|    12|   11|10.. 11b ..0|`,
+		`             ^      ^     ^            ^|`,
+		"cmds:``T???:'F` 'EXT=`.ACK=```` Id:0xFHH`"}
+	if o := lintMap(l, ds, asciiR); o != want {
+		t.Errorf("ascii map of %q:\n%q\nwant\n%q", pic, o, want)
+	}
+	for _, pic := range []string{pic, `Id:EFHH 'ACK=`, `D.22@`} {
+		l, ds := Check(pic)
+		o, u := lintMap(l, ds, ansiR), lintMap(l, ds, utf8R)
+		for i := range o {
+			if s := ansiEsc.ReplaceAllString(o[i], ``); s != u[i] {
+				t.Errorf("ansi line %d of %q is not utf8 colored:\n%q\n%q", i, pic, s, u[i])
+			}
+		}
+		if err := len(ds) > 0; err != strings.HasPrefix(o[0], "\x1b[1;31mError:") {
+			t.Errorf("ansi error of %q not highlighted: %q", pic, o[0])
+		}
+	}
+}
+
+func TestAutoRenderer(t *testing.T) {
+	for _, v := range []struct {
+		term, nocolor string
+		want          renderer
+	}{
+		{``, ``, asciiR},
+		{`dumb`, ``, asciiR},
+		{`xterm`, `1`, utf8R},
+		{`xterm`, ``, utf8R}, // stdout of tests is not a terminal
+	} {
+		t.Setenv(`TERM`, v.term)
+		t.Setenv(`NO_COLOR`, v.nocolor)
+		if r := autoRenderer(); r != v.want {
+			t.Errorf("TERM=%q NO_COLOR=%q: got %T, want %T", v.term, v.nocolor, r, v.want)
+		}
+	}
+}
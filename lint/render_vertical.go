@@ -0,0 +1,61 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VerticalMap renders the layout one field a line, from the most
+// significant bit: bit range, width, kind, picture of the field and its
+// name. Bits above the last field, up to the declared width, are shown
+// as unused, !dd@ skips as skipped.
+func VerticalMap(l *Layout) string {
+	return verticalMap(l, utf8R)
+}
+
+// verticalMap is VerticalMap with text drawn by r.
+func verticalMap(l *Layout, r renderer) string {
+	names := l.Names()
+	rs := [][]string{{`bits`, `width`, `kind`, `picture`, `label`}}
+	span := func(hi, lo int) string {
+		if hi == lo {
+			return fmt.Sprint(l.bitNo(lo, false))
+		}
+		return l.bitSpan(hi, lo)
+	}
+	if l.Width > l.Bits {
+		rs = append(rs, []string{span(l.Width-1, l.Bits), fmt.Sprint(l.Width - l.Bits), `-`, ``, `(unused)`})
+	}
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		name := f.Show(names[i])
+		if f.Kind == KindSkip {
+			name = `(skipped)`
+		}
+		rs = append(rs, []string{span(f.Hi(), f.Lo), fmt.Sprint(f.Width), f.Kind.String(),
+			r.text(strings.TrimSpace(f.Pic())), r.text(name)})
+	}
+	ws := make([]int, len(rs[0]))
+	for _, row := range rs {
+		for i, c := range row {
+			ws[i] = max(ws[i], r.width(c))
+		}
+	}
+	var b strings.Builder
+	for _, row := range rs {
+		var ln strings.Builder
+		for i, c := range row {
+			pad := strings.Repeat(` `, ws[i]-r.width(c))
+			if i == 1 { // numbers to the right
+				c, pad = pad+c, ``
+			}
+			ln.WriteString(c + pad + `  `)
+		}
+		b.WriteString(strings.TrimRight(ln.String(), ` `) + "\n")
+	}
+	return b.String()
+}
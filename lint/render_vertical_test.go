@@ -0,0 +1,32 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import "testing"
+
+func TestVerticalMap(t *testing.T) {
+	l, _ := check(`Type:'F 'EXT=.ACK= Id:0xFHH !08@ Len:HH`, 40, nil, Numbering{})
+	want := `bits    width  kind  picture   label
+39..32      8  -               (unused)
+31..29      3  num   Type:'F   Type
+28          1  flag  'EXT=     EXT
+27          1  flag  .ACK=     ACK
+26..16     11  hex   Id:0xFHH  Id
+15..8       8  skip  !08@      (skipped)
+7..0        8  hex   Len:HH    Len
+`
+	if got := VerticalMap(l); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	l, _ = check(`Tśćę:'F 'EXT=`, 8, nil, Numbering{})
+	want = `bits  width  kind  picture  label
+7..4      4  -              (unused)
+3..1      3  num   T???:'F  Num1
+0         1  flag  'EXT=    EXT
+`
+	if got := verticalMap(l, asciiR); got != want {
+		t.Errorf("ascii got:\n%s\nwant:\n%s", got, want)
+	}
+}
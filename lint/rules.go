@@ -0,0 +1,169 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Diagnostic is a single finding about a picstring.
+type Diagnostic struct {
+	Rule string // ID of the rule that found it, eg. BP002
+	Pos  int    // picstring offset of the offending part
+	End  int    // offset past it, 0 if it is about whole picstring
+	Msg  string
+}
+
+func (d *Diagnostic) Error() string { return d.Msg }
+
+// Rule checks a parsed picstring. Rules are run by Check in order
+// of registration, built-in ones first.
+type Rule interface {
+	ID() string // short unique name, eg. BP002
+	Check(l *Layout) []Diagnostic
+}
+
+// NewRule makes a Rule of a plain function.
+func NewRule(id string, ck func(l *Layout) []Diagnostic) Rule {
+	return &rule{id, ck}
+}
+
+type rule struct {
+	id string
+	ck func(l *Layout) []Diagnostic
+}
+
+func (r *rule) ID() string                   { return r.id }
+func (r *rule) Check(l *Layout) []Diagnostic { return r.ck(l) }
+
+var rules []Rule
+
+// Register adds a rule to the set run by Check. It panics if the rule
+// ID is empty or already taken.
+func Register(r Rule) {
+	id := r.ID()
+	if len(id) == 0 {
+		panic("bplint: Register rule with no ID")
+	}
+	for _, v := range rules {
+		if v.ID() == id {
+			panic(fmt.Sprintf("bplint: Register called twice for rule %s", id))
+		}
+	}
+	rules = append(rules, r)
+}
+
+// Rules returns registered rules.
+func Rules() []Rule {
+	return append([]Rule(nil), rules...)
+}
+
+// Check parses picstring then runs all registered rules over it.
+// Diagnostics come rightmost first, ones about the whole picstring
+// go last. Syntax errors from Parse come under the BP000 rule.
+func Check(pic string) (*Layout, []Diagnostic) {
+	return check(pic, 64, nil, Numbering{})
+}
+
+// check is Check of a picstring declared width bits wide, with fields
+// checked against ms constants if given. Layout is numbered by n.
+func check(pic string, width int, ms *maskSet, n Numbering) (l *Layout, ds []Diagnostic) {
+	l, err := Parse(pic)
+	l.Width, l.masks, l.Num = width, ms, n
+	if err != nil {
+		ds = append(ds, *err.(*Diagnostic))
+	}
+	for _, r := range rules {
+		for _, d := range r.Check(l) {
+			if len(d.Rule) == 0 {
+				d.Rule = r.ID()
+			}
+			ds = append(ds, d)
+		}
+	}
+	sort.SliceStable(ds, func(i, j int) bool {
+		if (ds[i].End > 0) != (ds[j].End > 0) {
+			return ds[i].End > 0
+		}
+		return ds[i].Pos > ds[j].Pos
+	})
+	return
+}
+
+// At makes a diagnostic pointing at the command of field f.
+func (f *Field) At(msg string) Diagnostic {
+	return Diagnostic{Pos: f.Pos, End: f.Pos + len(f.Cmd), Msg: msg}
+}
+
+func init() {
+	Register(NewRule(`BP001`, ckOverflow))
+	Register(NewRule(`BP002`, ckHexShape))
+	Register(NewRule(`BP003`, ckNumShape))
+}
+
+const msgShape = "See section 'Valid Numbers' in docs."
+
+// BP001: bitpeek takes a single uint64, or less if marker says so
+func ckOverflow(l *Layout) []Diagnostic {
+	if l.Bits > l.Width {
+		return []Diagnostic{{Msg: fmt.Sprintf("Pic string takes more than %d bits!", l.Width)}}
+	}
+	return nil
+}
+
+// BP002: no hex continuation can be glued before a hex number.
+// Eg. BHH 9b, EHH 10b, FHH 11b, HHH 12b, BHHH 13b and so on.
+func ckHexShape(l *Layout) (ds []Diagnostic) {
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		if f.Kind != KindHex {
+			continue
+		}
+		switch f.prev {
+		case 'H', 'F', 'E', 'B':
+			ds = append(ds, f.At("Bad shape of a Hex number. "+msgShape))
+		}
+	}
+	return
+}
+
+// BP003: any consecutive mix of BEF is not allowed unless escaped with
+// a '*' marker after the offending sequence of commands. Chain of Bs
+// is allowed.
+func ckNumShape(l *Layout) (ds []Diagnostic) {
+	for i := range l.Fields {
+		f := &l.Fields[i]
+		n := f.prev
+		ok := true
+		switch f.Cmd[0] {
+		case 'B': // no A..H glued, save for B
+			ok = f.Kind != KindBit || n == 'B' ||
+				n < 49 || n|1 == 0x3b || n > 72
+		case 'E', 'F':
+			off := f.next
+			if f.Kind == KindNum && f.Cmd[0] == 'E' && len(f.Cmd) > 1 {
+				off = f.Cmd[1]
+			}
+			switch {
+			case f.Kind == KindHex:
+			case off == '*':
+				// OK. checks turned off
+			case len(f.Cmd) == 1:
+				// ok, lone E or F
+				ok = f.Cmd[0] == 'F' || n < 65 || n > 72
+			case f.Kind == KindOct:
+				// ok, Octal prepended by chr <= '0', :;, chr >= 'I'
+				ok = n < 49 || n|1 == 0x3b || n > 72
+			default:
+				ok = false
+			}
+		}
+		if !ok {
+			ds = append(ds, f.At("Misleading use of B/E/F number. "+msgShape))
+		}
+	}
+	return
+}
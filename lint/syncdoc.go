@@ -0,0 +1,171 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Map block delimiters. The block sits right above its marker:
+//
+//	// bplint:map
+//	//
+//	//	bits:|63 3b 61|    60|...
+//	//	             ^      ^ ...
+//	//	cmds:¨¨Type:'F¨ 'EXT=¨...
+//	//
+//	// bplint:end
+//	//
+//	//bitpeek:Example
+//
+// Empty comment lines are there so gofmt leaves doc comments as made.
+const (
+	docBegin = `// bplint:map`
+	docEnd   = `// bplint:end`
+)
+
+// docBlock returns lines of the map block of l, indented with ind.
+func docBlock(ind string, l *Layout) (r []string) {
+	r = append(r, ind+docBegin, ind+`//`)
+	m := lintMap(l, nil, utf8R) // comments are UTF-8 whatever the terminal
+	for _, v := range m[1:] {
+		r = append(r, strings.TrimRight(ind+"//\t"+v, " "))
+	}
+	return append(r, ind+`//`, ind+docEnd, ind+`//`)
+}
+
+// docFound returns the first line of the map block that ends above the
+// marker at line at (0-based), or at if there is none.
+func docFound(lines []string, at int) int {
+	i := at - 1
+	if i >= 0 && strings.TrimSpace(lines[i]) == `//` {
+		i--
+	}
+	if i < 0 || strings.TrimSpace(lines[i]) != docEnd {
+		return at
+	}
+	for j := i - 1; j >= 0; j-- {
+		switch t := strings.TrimSpace(lines[j]); {
+		case t == docBegin:
+			return j
+		case !strings.HasPrefix(t, `//`):
+			return at
+		}
+	}
+	return at
+}
+
+// syncFile refreshes map blocks above markers of fn that match -m. With
+// write unset the file is left as is. It returns lines of markers whose
+// blocks were stale or missing. Picstrings that do not check clean get
+// their findings printed and their blocks are not touched.
+func syncFile(fn string, write bool) (stale []int, err error) {
+	ps, fsup, err := scanFile(fn)
+	if err != nil {
+		return
+	}
+	files++
+	src, err := os.ReadFile(fn)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(string(src), "\n")
+	for i := len(ps) - 1; i >= 0; i-- { // bottom up, keeps lines above valid
+		p := &ps[i]
+		if p.bad != nil || p.mk.begin || !strings.Contains(p.tag, match) {
+			continue
+		}
+		at := p.mk.pos.Line - 1
+		ml := lines[at]
+		if !strings.HasPrefix(strings.TrimSpace(ml), `//bitpeek`) {
+			continue // marker after code, no place for a block
+		}
+		seen++
+		l, ds := checkMarked(fn, p, fsup)
+		if len(ds) > 0 {
+			errcnt++
+			if !quiet {
+				fmt.Printf("--- Pic: \"%s\" in %s line %d -\n", p.tag, p.pos.Filename, p.pos.Line)
+				prDiags(ds)
+				fmt.Println()
+			}
+			continue
+		}
+		nb := docBlock(ml[:len(ml)-len(strings.TrimLeft(ml, " \t"))], l)
+		from := docFound(lines, at)
+		if strings.Join(lines[from:at], "\n") == strings.Join(nb, "\n") {
+			continue
+		}
+		stale = append([]int{p.mk.pos.Line}, stale...)
+		lines = append(lines[:from], append(nb, lines[at:]...)...)
+	}
+	if write && len(stale) > 0 {
+		err = os.WriteFile(fn, []byte(strings.Join(lines, "\n")), 0644)
+	}
+	return
+}
+
+// runSyncDoc is the 'bplint sync-doc' command. It writes or refreshes
+// map blocks above markers, with -check it only reports stale ones.
+func runSyncDoc(args []string) {
+	write := true
+	var fns []string
+	fwd := false
+	for i, v := range args {
+		switch {
+		case fwd:
+			fwd = false
+			continue
+		case v == `-check`:
+			write = false
+		case v == `-q`:
+			quiet = true
+		case (v == `-bits` || v == `-endian`) && i < len(args)-1:
+			setNum(v, args[i+1])
+			fwd = true
+		case v == `-m` && i < len(args)-1:
+			match = args[i+1]
+			fwd = true
+		default:
+			r, err := goFiles(v)
+			if err != nil {
+				prErr(fmt.Sprintf("Can not %s", err), quiet)
+				errcnt++
+			}
+			fns = append(fns, r...)
+		}
+	}
+	if len(fns) == 0 {
+		prErr(`Error: no files given and/or no files checked!`, quiet)
+		usage()
+	}
+	nst := 0
+	for _, fn := range fns {
+		st, err := syncFile(fn, write)
+		if err != nil {
+			prErr(fmt.Sprintf("Can not %s", err), quiet)
+			errcnt++
+			continue
+		}
+		nst += len(st)
+		for _, ln := range st {
+			switch {
+			case quiet:
+			case write:
+				fmt.Printf("Synced: map above marker in %s line %d\n", fn, ln)
+			default:
+				fmt.Printf("Stale: map above marker in %s line %d\n", fn, ln)
+			}
+		}
+	}
+	if !write && nst > 0 && !quiet {
+		fmt.Printf("--- %d maps out of sync, run: bplint sync-doc -\n", nst)
+	}
+	if errcnt > 0 || !write && nst > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+package lint
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSyncDoc(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), `x.go`)
+	src := "package x\n\nconst (\n\t//bitpeek:Hdr width=16\n\tHdr = `Id:0xFHH 'ACK=`\n)\n"
+	os.WriteFile(fn, []byte(src), 0644)
+	if st, err := syncFile(fn, false); err != nil || len(st) != 1 || st[0] != 4 {
+		t.Fatalf("missing block: got %v, %v", st, err)
+	}
+	if b, _ := os.ReadFile(fn); string(b) != src {
+		t.Fatalf("check mode changed the file:\n%s", b)
+	}
+	if _, err := syncFile(fn, true); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(fn)
+	want := "\t// bplint:map\n\t//\n" +
+		"\t//\tbits:|11 11b 1|     0|\n" +
+		"\t//\t             ^      ^|\n" +
+		"\t//\tcmds:¨Id:0xFHH¨ 'ACK=¨\n" +
+		"\t//\n\t// bplint:end\n\t//\n\t//bitpeek:Hdr width=16\n"
+	if !strings.Contains(string(b), want) {
+		t.Fatalf("block not written:\n%s", b)
+	}
+	if f, _ := format.Source(b); string(f) != string(b) {
+		t.Errorf("gofmt changes the block:\n%s", f)
+	}
+	if st, _ := syncFile(fn, false); len(st) != 0 {
+		t.Errorf("fresh block reported stale at %v", st)
+	}
+	os.WriteFile(fn, []byte(strings.Replace(string(b), "'ACK=`", "'NAK=`", 1)), 0644)
+	if st, _ := syncFile(fn, false); len(st) != 1 || st[0] != 12 {
+		t.Errorf("stale block not reported: %v", st)
+	}
+	syncFile(fn, true)
+	if b, _ := os.ReadFile(fn); strings.Count(string(b), docBegin) != 1 || !strings.Contains(string(b), "¨ 'NAK=¨\n") {
+		t.Errorf("block not refreshed in place:\n%s", b)
+	}
+}
+
+// Sync-doc takes nolints as check does, so it writes blocks of all
+// picstrings check passes.
+func TestSyncDocNolint(t *testing.T) {
+	defer func(n int) { errcnt = n }(errcnt)
+	errcnt = 0
+	src, _ := os.ReadFile(`testdata/nolint.go`)
+	fn := filepath.Join(t.TempDir(), `x.go`)
+	os.WriteFile(fn, src, 0644)
+	if st, err := syncFile(fn, false); err != nil || len(st) != 4 || errcnt != 0 {
+		t.Errorf("got stale %v, %d errors, %v; expected 4 stale, 0", st, errcnt, err)
+	}
+}
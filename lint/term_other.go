@@ -0,0 +1,11 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package lint
+
+// ttyColumns can not tell terminal size here, maps are not wrapped
+// unless -width or COLUMNS is given.
+func ttyColumns() int { return 0 }
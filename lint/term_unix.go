@@ -0,0 +1,24 @@
+// Copyright 2018 OHIR-RIPE. All rights reserved.
+// Use of this source code is governed by a MIT
+// license that can be found in the LICENSE file.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lint
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// ttyColumns returns columns of the terminal stdout is, 0 if it is not.
+func ttyColumns() int {
+	var ws struct{ row, col, x, y uint16 }
+	_, _, e := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if e != 0 {
+		return 0
+	}
+	return int(ws.col)
+}
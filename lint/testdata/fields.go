@@ -0,0 +1,30 @@
+package testdata
+
+type reg struct {
+	Desc, Note string
+	Pic        string
+	Alt        []string
+}
+
+func newReg(desc, pic string) reg { return reg{Desc: desc, Pic: pic} }
+
+func wrap(r reg) reg { return r }
+
+var (
+	//bitpeek:multi field=Pic
+	a = reg{
+		Desc: `Status`,
+		Note: `not this`,
+		Pic:  `Id:FHH`,
+	}
+	//bitpeek:call field=pic
+	b = newReg(`Control`, `Mode:EH`)
+	//bitpeek:nth field=2
+	c = newReg(`Control`, `Nth:FH`)
+	//bitpeek:alt field=Alt skip=1
+	d = reg{Alt: []string{`no`, `'Alt':BH`}}
+	//bitpeek:nested field=Pic
+	f = wrap(reg{Desc: `Wrapped`, Pic: `Wrap:EH`})
+	//bitpeek:missing field=Nope
+	e = reg{Desc: `x`, Pic: `F`}
+)
@@ -0,0 +1,4 @@
+package testdata
+
+//bitpeek:status width=40
+var status = `Type:'F 'EXT=.ACK= Id:0xFHH !08@ Len:HH`
@@ -0,0 +1,31 @@
+-- Code generated by bplint gen wireshark -t Example; DO NOT EDIT.
+--
+--   Type:'F 'EXT=.ACK= Id:0xFHH from IPv4.Address32@:D.16@
+--
+--   bits:|63 3b 61|    60|   59|58 11b 48|47..     32b     ..16|15 16b 0|
+--                ^      ^     ^         ^                     ^        ^|
+--   cmds:¨¨Type:'F¨ 'EXT=¨.ACK=¨ Id:0xFHH¨ from IPv4.Address32@¨¨¨:D.16@¨
+
+local example = Proto("example", "Example")
+
+local example_type = ProtoField.uint64("example.type", "Type", base.DEC, nil, UInt64.fromhex("e000000000000000"))
+local example_ext = ProtoField.bool("example.ext", "EXT", 64, nil, UInt64.fromhex("1000000000000000"))
+local example_ack = ProtoField.bool("example.ack", "ACK", 64, nil, UInt64.fromhex("0800000000000000"))
+local example_id = ProtoField.uint64("example.id", "Id", base.HEX, nil, UInt64.fromhex("07ff000000000000"))
+local example_addr16 = ProtoField.ipv4("example.addr16", "Addr16")
+local example_dec0 = ProtoField.uint64("example.dec0", "Dec0", base.DEC, nil, UInt64.fromhex("000000000000ffff"))
+
+example.fields = { example_type, example_ext, example_ack, example_id, example_addr16, example_dec0 }
+
+function example.dissector(buf, pinfo, tree)
+  if buf:len() < 8 then return 0 end
+  pinfo.cols.protocol = "Example"
+  local t = tree:add(example, buf(0, 8))
+  t:add(example_type, buf(0, 8))
+  t:add(example_ext, buf(0, 8))
+  t:add(example_ack, buf(0, 8))
+  t:add(example_id, buf(0, 8))
+  t:add(example_addr16, buf(2, 4))
+  t:add(example_dec0, buf(0, 8))
+  return 8
+end
@@ -0,0 +1,29 @@
+-- Code generated by bplint gen wireshark -t status; DO NOT EDIT.
+--
+--   Type:'F 'EXT=.ACK= Id:0xFHH !08@ Len:HH
+--
+--   bits:|31 3b 29|    28|   27|26 11b 16|15 8b 8|7.. 8b ..0|
+--                ^      ^     ^         ^       ^          ^|
+--   cmds:¨¨Type:'F¨ 'EXT=¨.ACK=¨ Id:0xFHH¨¨¨ !08@¨¨¨¨ Len:HH¨
+
+local status = Proto("status", "Status")
+
+local status_type = ProtoField.uint64("status.type", "Type", base.DEC, nil, UInt64.fromhex("00e0000000"))
+local status_ext = ProtoField.bool("status.ext", "EXT", 40, nil, UInt64.fromhex("0010000000"))
+local status_ack = ProtoField.bool("status.ack", "ACK", 40, nil, UInt64.fromhex("0008000000"))
+local status_id = ProtoField.uint64("status.id", "Id", base.HEX, nil, UInt64.fromhex("0007ff0000"))
+local status_len = ProtoField.uint64("status.len", "Len", base.HEX, nil, UInt64.fromhex("00000000ff"))
+
+status.fields = { status_type, status_ext, status_ack, status_id, status_len }
+
+function status.dissector(buf, pinfo, tree)
+  if buf:len() < 5 then return 0 end
+  pinfo.cols.protocol = "Status"
+  local t = tree:add(status, buf(0, 5))
+  t:add(status_type, buf(0, 5))
+  t:add(status_ext, buf(0, 5))
+  t:add(status_ack, buf(0, 5))
+  t:add(status_id, buf(0, 5))
+  t:add(status_len, buf(0, 5))
+  return 5
+end
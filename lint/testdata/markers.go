@@ -0,0 +1,19 @@
+package testdata
+
+type reg struct {
+	Desc string
+	Pic  string
+	Note string
+}
+
+var regs = []reg{
+	//bitpeek:keyed field=Pic
+	{Desc: `Status`, Note: `not this`, Pic: `Id:FHH`},
+	//bitpeek:skip12 skip=3
+	{`a`, `b`, `c`},
+	{`Mode:FH`, ``, ``},
+	//bitpeek:bad:9x
+	{`a`, `b`, `c`},
+	//bitpeek:narrow width=8
+	{`x`, `HHH`, ``},
+}
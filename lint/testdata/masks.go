@@ -0,0 +1,21 @@
+package testdata
+
+const (
+	HdrTypeShift = 61
+	HdrTypeMask  = 0x7 << HdrTypeShift
+	HdrEXTMask   = 1 << 60
+	HdrIdShift   = 47
+	HdrIdMask    = 0x7ff << HdrIdShift
+	HdrLenShift  = 16
+	HdrLenMask   = 0xffff // unshifted
+	HdrSeqShift  = 4
+	HdrSeqMask   = 0xf0f0
+)
+
+var (
+	//bitpeek:hdr masks=Hdr*
+	hdr = `Type:'F 'EXT=.ACK= Id:0xFHH Len:HHHH Seq:HHHH !08@ Lo:HH`
+
+	//bitpeek:none masks=Ctl*
+	ctl = `Mode:EH`
+)
@@ -0,0 +1,15 @@
+//nolint:BP001
+
+package testdata
+
+var pics = []string{
+	//bitpeek:hex:0:nolint=BP002
+	`EFHH`,
+	//bitpeek:two:0:nolint=BP002,BP003
+	`FFF`,
+	//nolint:lll,bplint
+	//bitpeek:all:0
+	`EEEE HHHHHHHHHHHHHHHHH`,
+	//bitpeek:ok:0
+	`ER=HHHHHHHHHHHHHHHHH`,
+}
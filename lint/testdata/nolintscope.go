@@ -0,0 +1,17 @@
+package testdata
+
+func f() {
+	println() //nolint
+	println()
+
+	//bitpeek:late:0
+	_ = `EFHH`
+
+	println() //nolint:BP002
+	//nolint:BP003
+	_ = 1
+	//nolint:BP002
+
+	//bitpeek:near:0
+	_ = `EFHH`
+}
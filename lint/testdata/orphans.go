@@ -0,0 +1,23 @@
+package testdata
+
+var a = []string{
+	//bitpeek:twice:0
+	//bitpeek:over:3
+	`a`, `b`,
+}
+
+// bitpeek:spaced
+var b = `F`
+
+//bitpeak:typo
+var c = `F`
+
+//bitpeek:stmt:1
+var d = `a`
+var e = `F`
+
+// Prose about bitpeek is fine, so is "//bitpeek:tag" quoted.
+
+/*bitpeek:block*/
+
+//bitpeek:eof
@@ -0,0 +1,22 @@
+package testdata
+
+var table = []struct {
+	desc string
+	pic  string
+	out  [2]string
+}{
+	//bitpeek:begin tbl field=2 nolint=BP003
+	{`first`, `Id:FHH`, [2]string{`x`, `y`}},
+	{`second`,
+		`FFF`, [2]string{`x`, `y`}},
+	//bitpeek:own:1
+	{`third`, `Mode:BHH`, [2]string{}},
+	{`empty`},
+	//bitpeek:end
+}
+
+var keyed = []struct{ Name, Pic string }{
+	//bitpeek:begin kv field=Pic
+	{Pic: `F`, Name: `one`},
+	{Name: `two`, Pic: `HH`},
+}